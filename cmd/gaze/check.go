@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Exit codes for the headless check mode
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+// runCheck verifies that something is listening on a port and, optionally,
+// that it answers HTTP with the expected status code. It returns the
+// process exit code.
+func runCheck(cfg scanner.Config, port int, httpExpectation string, verbose bool) int {
+	expectedStatus := 0
+	if httpExpectation != "" {
+		p, status, err := parseHTTPExpectation(httpExpectation)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		if port != 0 && port != p {
+			fmt.Fprintf(os.Stderr, "Error: --check-port %d and --check-http %s refer to different ports\n", port, httpExpectation)
+			return exitUsage
+		}
+		port, expectedStatus = p, status
+	}
+
+	// Only the checked port is probed, once, below
+	scanCfg := cfg
+	scanCfg.NoHTTPCheck = true
+	ports, err := scanner.ScanPorts(scanCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+
+	info := listenerOn(ports, port)
+	if info == nil {
+		if verbose {
+			fmt.Printf("nothing is listening on port %d\n", port)
+		}
+		return exitFailed
+	}
+
+	if expectedStatus == 0 {
+		if verbose {
+			fmt.Printf("port %d is held by %s (PID %d)\n", port, info.Process, info.PID)
+		}
+		return exitOK
+	}
+
+	result := scanner.CheckHTTPHealth(port, cfg)
+	status := result.StatusCode
+	if status != expectedStatus {
+		if verbose {
+			if status == 0 {
+				fmt.Printf("port %d did not answer HTTP (expected %d)\n", port, expectedStatus)
+			} else {
+				fmt.Printf("port %d answered HTTP %d (expected %d)\n", port, status, expectedStatus)
+			}
+		}
+		return exitFailed
+	}
+
+	if verbose {
+		fmt.Printf("port %d answered HTTP %d in %dms\n", port, status, result.Latency.Milliseconds())
+	}
+	return exitOK
+}
+
+// listenerOn returns the TCP listener on port, or nil if there is none.
+// A UDP socket with the same number can't accept connections, so it
+// doesn't count.
+func listenerOn(ports []scanner.PortInfo, port int) *scanner.PortInfo {
+	for i := range ports {
+		if ports[i].Port == port && ports[i].SocketType == scanner.SocketTCP {
+			return &ports[i]
+		}
+	}
+	return nil
+}
+
+// parseHTTPExpectation parses a PORT=STATUS expectation such as "8080=200"
+func parseHTTPExpectation(s string) (int, int, error) {
+	portStr, statusStr, ok := strings.Cut(s, "=")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid --check-http %q: expected PORT=STATUS", s)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, 0, fmt.Errorf("invalid port in --check-http %q", s)
+	}
+	status, err := strconv.Atoi(statusStr)
+	if err != nil || status < 100 || status > 599 {
+		return 0, 0, fmt.Errorf("invalid HTTP status in --check-http %q", s)
+	}
+	return port, status, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestListenerOn(t *testing.T) {
+	ports := []scanner.PortInfo{
+		{Port: 53, PID: 1, SocketType: scanner.SocketUDP},
+		{Port: 8080, PID: 2, SocketType: scanner.SocketUDP},
+		{Port: 8080, PID: 3, SocketType: scanner.SocketTCP},
+	}
+
+	tests := []struct {
+		name    string
+		port    int
+		wantPID int32 // 0 for no listener
+	}{
+		{"tcp", 8080, 3},
+		{"udp only", 53, 0},
+		{"nothing", 9000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int32
+			if info := listenerOn(ports, tt.port); info != nil {
+				got = info.PID
+			}
+			if got != tt.wantPID {
+				t.Errorf("listenerOn(%d) PID = %d, want %d", tt.port, got, tt.wantPID)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix starts the environment variables that stand in for flags
+const envPrefix = "GAZE_"
+
+// envName returns the environment variable for a flag, e.g. GAZE_READ_ONLY
+// for --read-only
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// envExcluded lists the flags that kill processes or confirm that they
+// may be killed. A variable left in a shell profile or unit file must not
+// do that unseen, so these are only taken from the command line.
+var envExcluded = map[string]bool{
+	"kill-range":        true,
+	"yes":               true,
+	"force":             true,
+	"auto-kill":         true,
+	"auto-kill-confirm": true,
+}
+
+// applyEnv sets each flag that wasn't given on the command line from its
+// environment variable, if set, and records it in set. Flags therefore
+// take precedence over the environment, which in turn takes precedence
+// over the settings file and defaults.
+func applyEnv(fs *flag.FlagSet, set map[string]bool) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] || envExcluded[f.Name] {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", envName(f.Name), setErr)
+			return
+		}
+		set[f.Name] = true
+	})
+	return err
+}
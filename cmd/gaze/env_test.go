@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		args    []string
+		want    string
+		wantSet bool
+	}{
+		{"from environment", "interval", "5s", nil, "5s", true},
+		{"command line wins", "interval", "5s", []string{"--interval", "2s"}, "2s", true},
+		{"unset", "interval", "", nil, "1s", false},
+		{"kill range excluded", "kill-range", "3000-3010", nil, "", false},
+		{"yes excluded", "yes", "true", nil, "false", false},
+		{"auto-kill excluded", "auto-kill", "3000", nil, "", false},
+		{"auto-kill confirm excluded", "auto-kill-confirm", "true", nil, "false", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("gaze", flag.ContinueOnError)
+			fs.Duration("interval", time.Second, "")
+			fs.String("kill-range", "", "")
+			fs.Bool("yes", false, "")
+			fs.String("auto-kill", "", "")
+			fs.Bool("auto-kill-confirm", false, "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if tt.env != "" {
+				t.Setenv(envName(tt.flag), tt.env)
+			}
+
+			set := make(map[string]bool)
+			fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+			if err := applyEnv(fs, set); err != nil {
+				t.Fatalf("applyEnv: %v", err)
+			}
+			if got := fs.Lookup(tt.flag).Value.String(); got != tt.want {
+				t.Errorf("--%s = %q, want %q", tt.flag, got, tt.want)
+			}
+			if set[tt.flag] != tt.wantSet {
+				t.Errorf("--%s set = %v, want %v", tt.flag, set[tt.flag], tt.wantSet)
+			}
+		})
+	}
+}
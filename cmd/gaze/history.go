@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/junjiang/gaze/internal/config"
+	"github.com/junjiang/gaze/internal/history"
+)
+
+// runHistory prints the port history saved by the last interactive
+// session, as JSON if asJSON is set. It returns the process exit code.
+func runHistory(asJSON bool) int {
+	path, err := config.HistoryPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+	snap, err := history.ReadSnapshot(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailed
+		}
+		fmt.Println(string(data))
+		return exitOK
+	}
+
+	if len(snap.Histories) == 0 {
+		fmt.Println("No port history saved yet; run gaze interactively first")
+		return exitOK
+	}
+	fmt.Printf("Port history saved %s:\n", snap.SavedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("%6s  %-24s  %-19s  %-19s  %5s  %s\n", "PORT", "PROCESS", "FIRST SEEN", "LAST SEEN", "OPENS", "STATE")
+	for _, h := range snap.Histories {
+		state := "closed"
+		if h.IsActive {
+			state = "open"
+			if h.LastDownDuration > 0 {
+				state += fmt.Sprintf(" (back up after %s down)", history.FormatUptime(h.LastDownDuration))
+			}
+		}
+		fmt.Printf("%6s  %-24s  %-19s  %-19s  %5d  %s\n", h.Key(), h.Process,
+			h.FirstSeen.Format("2006-01-02 15:04:05"), h.LastSeen.Format("2006-01-02 15:04:05"), h.OpenCount, state)
+	}
+	return exitOK
+}
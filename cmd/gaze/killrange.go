@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// killRangeOptions controls the headless --kill-range mode
+type killRangeOptions struct {
+	readOnly bool
+	dryRun   bool
+	yes      bool // Skip the confirmation prompt
+	force    bool // Send SIGKILL rather than SIGTERM
+}
+
+// runKillRange kills every process listening on a port within spec,
+// e.g. "3000-3010", after listing the affected processes and asking for
+// confirmation. It returns the process exit code.
+func runKillRange(cfg scanner.Config, spec string, opts killRangeOptions, table tableOptions) int {
+	r, err := scanner.ParsePortRange(spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if opts.readOnly {
+		fmt.Fprintln(os.Stderr, "Error: --kill-range is disabled in read-only mode")
+		return exitUsage
+	}
+
+	ports, err := scanner.ScanPorts(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+
+	targets, denied := portsInRange(ports, r)
+	if denied > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d port(s) held by other users' processes; run gaze as those users or root to kill them\n", denied)
+	}
+	if len(targets) == 0 {
+		fmt.Printf("No processes gaze may kill are listening on ports %s\n", r)
+		return exitOK
+	}
+
+	fmt.Printf("Processes listening on ports %s:\n", r)
+	if err := table.write(targets); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+
+	if opts.dryRun {
+		fmt.Println("Dry run: nothing was killed")
+		return exitOK
+	}
+	sig, done := syscall.SIGTERM, "sent SIGTERM"
+	if opts.force {
+		sig, done = syscall.SIGKILL, "killed"
+	}
+	if !scanner.SignalsSupported() {
+		done = "killed"
+	}
+	question := fmt.Sprintf("Kill %d process(es)?", scanner.CountProcesses(targets))
+	if scanner.SignalsSupported() {
+		question = fmt.Sprintf("Send %s to %d process(es)?", signalName(sig), scanner.CountProcesses(targets))
+	}
+	if !opts.yes && !confirm(question) {
+		fmt.Println("Aborted")
+		return exitFailed
+	}
+
+	pids := make([]int32, len(targets))
+	for i, p := range targets {
+		pids[i] = p.PID
+	}
+	results := scanner.KillMultipleProcesses(pids, sig)
+
+	failed := 0
+	for _, p := range targets {
+		err := results[p.PID]
+		switch {
+		case err == nil:
+			fmt.Printf("  :%d  %s (PID %d): %s\n", p.Port, p.Process, p.PID, done)
+		case scanner.IsProcessGone(err):
+			fmt.Printf("  :%d  %s (PID %d): already exited\n", p.Port, p.Process, p.PID)
+		default:
+			failed++
+			fmt.Printf("  :%d  %s (PID %d): %v\n", p.Port, p.Process, p.PID, err)
+			var killErr *scanner.KillError
+			if errors.As(err, &killErr) && killErr.Hint() != "" {
+				fmt.Printf("      %s\n", killErr.Hint())
+			}
+		}
+	}
+
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// portsInRange returns the killable ports within r, ordered by port, and
+// how many others in r are held by processes gaze may not signal
+func portsInRange(ports []scanner.PortInfo, r scanner.PortRange) (targets []scanner.PortInfo, denied int) {
+	for _, p := range ports {
+		switch {
+		case !r.Contains(p.Port) || p.PID == 0:
+		case !p.CanKill:
+			denied++
+		default:
+			targets = append(targets, p)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Port < targets[j].Port })
+	return targets, denied
+}
+
+// signalName names the signals --kill-range sends
+func signalName(sig syscall.Signal) string {
+	if sig == syscall.SIGKILL {
+		return "SIGKILL"
+	}
+	return "SIGTERM"
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
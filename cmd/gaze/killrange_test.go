@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestPortsInRange(t *testing.T) {
+	ports := []scanner.PortInfo{
+		{Port: 3002, PID: 10, CanKill: true},
+		{Port: 3000, PID: 10, CanKill: true},
+		{Port: 3001, PID: 20, CanKill: false},
+		{Port: 3003, PID: 0},
+		{Port: 8080, PID: 30, CanKill: true},
+	}
+
+	tests := []struct {
+		name      string
+		r         scanner.PortRange
+		want      []int
+		denied    int
+		processes int
+	}{
+		{"mixed owners", scanner.PortRange{Start: 3000, End: 3010}, []int{3000, 3002}, 1, 1},
+		{"only others'", scanner.PortRange{Start: 3001, End: 3001}, nil, 1, 0},
+		{"none", scanner.PortRange{Start: 9000, End: 9010}, nil, 0, 0},
+		{"all", scanner.PortRange{Start: 1, End: 65535}, []int{3000, 3002, 8080}, 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, denied := portsInRange(ports, tt.r)
+			var got []int
+			for _, p := range targets {
+				got = append(got, p.Port)
+			}
+			if !slices.Equal(got, tt.want) || denied != tt.denied {
+				t.Errorf("portsInRange = %v, %d denied; want %v, %d denied", got, denied, tt.want, tt.denied)
+			}
+			if n := scanner.CountProcesses(targets); n != tt.processes {
+				t.Errorf("CountProcesses = %d, want %d", n, tt.processes)
+			}
+		})
+	}
+}
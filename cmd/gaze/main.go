@@ -1,20 +1,411 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/baseline"
+	"github.com/junjiang/gaze/internal/config"
+	"github.com/junjiang/gaze/internal/export"
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/logging"
+	"github.com/junjiang/gaze/internal/render"
+	"github.com/junjiang/gaze/internal/scanner"
+	"github.com/junjiang/gaze/internal/server"
 	"github.com/junjiang/gaze/internal/ui"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run parses the command line and runs the selected mode, returning the
+// process exit code. It is separate from main so deferred cleanup runs.
+func run() int {
+	showVersion := flag.Bool("version", false, "print version and build information, then exit")
+	showHistory := flag.Bool("history", false, "print the port history saved by the last interactive session, then exit")
+	asJSON := flag.Bool("json", false, "scan once and print the ports as JSON, like --once; with --version or --history, print that information as JSON")
+	checkPort := flag.Int("check-port", 0, "exit 0 if something is listening on `port`, 1 otherwise")
+	checkHTTP := flag.String("check-http", "", "like --check-port but also require an HTTP status, e.g. 8080=200")
+	verbose := flag.Bool("verbose", false, "print the result of --check-port/--check-http")
+	once := flag.Bool("once", false, "scan once, export the snapshot and exit")
+	exportTarget := flag.String("export", export.StdoutTarget, "with --once, directory to export to, or - for stdout")
+	format := flag.String("format", "json", "with --once, export format: json, csv, yaml, md, html or table")
+	columns := flag.String("columns", render.DefaultColumns, "columns of plain-text tables: "+strings.Join(render.ColumnNames(), ","))
+	noColor := flag.Bool("no-color", false, "disable color in plain-text tables")
+	noHTTPCheck := flag.Bool("no-http-check", false, "skip HTTP health checks, for faster scans and output that doesn't depend on how services answer")
+	httpPorts := flag.String("http-ports", "", "comma-separated ports or ranges to health check over HTTP, replacing the default list, e.g. 3000-3010,7070,9443")
+	tlsPorts := flag.String("tls-ports", "", "comma-separated ports or ranges whose health checks use HTTPS (default 443,8443)")
+	httpTimeout := flag.Duration("http-timeout", scanner.DefaultConfig().HTTPTimeout, "timeout for each HTTP health check")
+	interval := flag.Duration("interval", ui.DefaultRefreshInterval, "how often to rescan the ports, from 500ms to 60s; + and - adjust it while running")
+	stableScans := flag.Int("stable-scans", 1, "consecutive scans a new port must be seen in before it is tracked in history")
+	killRange := flag.String("kill-range", "", "kill every process listening on a port `range` such as 3000-3010, then exit")
+	yes := flag.Bool("yes", false, "with --kill-range, skip the confirmation prompt")
+	force := flag.Bool("force", false, "with --kill-range, send SIGKILL rather than SIGTERM")
+	autoExportEvery := flag.Duration("auto-export", 0, "write a JSON and CSV snapshot at this `interval`, e.g. 5m")
+	autoExportDir := flag.String("auto-export-dir", ".", "directory for --auto-export snapshots")
+	autoExportKeep := flag.Int("auto-export-keep", 10, "number of --auto-export snapshots of each format to keep")
+	prometheusFile := flag.String("prometheus-file", "", "write the ports as Prometheus metrics to this `file` every --interval, without the UI, for node_exporter's textfile collector (with --once, write it once)")
+	exportDir := flag.String("export-dir", ".", "directory the e key exports to, created if missing")
+	exportFormat := flag.String("export-format", "json,csv", "comma-separated formats the e key exports: json, csv, yaml, md and/or html")
+	exportName := flag.String("export-name", export.DefaultNameTemplate, "export filename `template` with {timestamp}, {host} and {format} placeholders")
+	ignorePorts := flag.String("ignore-ports", "", "comma-separated ports or ranges to hide from the table, history and exports, e.g. 631,5353 (replaces the saved list; \"\" to clear it)")
+	containersOnly := flag.Bool("containers-only", false, "start with only ports published by containers listed")
+	sortBy := flag.String("sort", "port", "initial sort column: port, pid, process, cpu, mem, uptime, addr, latency or conns, optionally followed by a secondary column, e.g. process,mem")
+	sortDesc := flag.Bool("sort-desc", false, "sort in descending order initially")
+	threads := flag.Bool("threads", false, "collect each process's thread count (an extra read per process every scan)")
+	highlightNew := flag.Duration("highlight-new", 5*time.Second, "how long newly opened ports are highlighted, 0 to disable")
+	unixSockets := flag.Bool("unix", false, "also list listening unix domain sockets (Linux only)")
+	bindCIDR := flag.String("bind-cidr", "", "only list ports bound to an address within this CIDR, e.g. 10.0.0.0/8 (wildcard binds always match)")
+	portRange := flag.String("range", "", "list only ports in these comma-separated `ranges`, e.g. 3000-9000 or 3000-3999,8080; history still tracks every port")
+	flag.StringVar(portRange, "only-ports", "", "alias of --range")
+	pid := flag.Int("pid", 0, "start with only the ports held by this process listed")
+	maxEvents := flag.Int("max-events", history.DefaultMaxEvents, "number of port events kept in history (overrides max_events in settings.json)")
+	maxHistories := flag.Int("max-histories", history.DefaultMaxHistories, "number of ports tracked in history (overrides max_histories in settings.json)")
+	maxPortEvents := flag.Int("max-port-events", history.DefaultMaxPortEvents, "number of events kept for each tracked port (overrides max_port_events in settings.json)")
+	maxRows := flag.Int("max-rows", 0, "build table rows for at most this many ports, the first by the current sort; 0 for no limit")
+	baselineFile := flag.String("baseline", "", "JSON `file` of expected ports; others are flagged, and fail --once")
+	logFile := flag.String("log-file", "", "write internal diagnostics to this file")
+	logLevel := flag.String("log-level", "info", "log level for --log-file: debug, info, warn or error")
+	serveAddr := flag.String("serve", "", "serve the live ports, history and events as JSON on this `address` while the UI runs, e.g. :8090 (localhost only) or 0.0.0.0:8090")
+	pprofAddr := flag.String("pprof-addr", "", "serve net/http/pprof on this `address`, e.g. localhost:6060, for profiling gaze itself")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of gaze to this `file`")
+	memProfile := flag.String("memprofile", "", "write a heap profile of gaze to this `file` on exit")
+	autoKill := flag.String("auto-kill", "", "kill processes as soon as they open a port matching this `pattern`: ports, :process-regexp or both, e.g. 3000-3999:node (needs --auto-kill-confirm)")
+	autoKillConfirm := flag.Bool("auto-kill-confirm", false, "confirm that --auto-kill may kill processes without asking")
+	alertNew := flag.Bool("alert-new", false, "highlight ports that weren't open at startup when they open, and log them with --alert-log")
+	alertLog := flag.String("alert-log", "", "append ports alerted by --alert-new to this `file`, one line each with time, port, PID and process")
+	watch := flag.String("watch", "", "comma-separated ports or ranges to send a desktop notification about when they open or close, e.g. 4000,8080")
+	readOnly := flag.Bool("read-only", false, "disable actions that kill or modify processes")
+	dryRun := flag.Bool("dry-run", false, "report process actions without performing them")
+	flag.Parse()
+
+	// Flags given on the command line, plus those set from GAZE_
+	// environment variables. Only the former are remembered in the
+	// settings file; the environment applies to this session alone.
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	cliFlags := maps.Clone(setFlags)
+	if err := applyEnv(flag.CommandLine, setFlags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	if *showVersion {
+		return runVersion(*asJSON)
+	}
+	if *showHistory {
+		return runHistory(*asJSON)
+	}
+
+	logCloser, err := logging.Setup(*logFile, *logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	defer logCloser.Close()
+
+	stopProfiling, err := startProfiling(profileOptions{
+		pprofAddr:  *pprofAddr,
+		cpuProfile: *cpuProfile,
+		memProfile: *memProfile,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	defer stopProfiling()
+
+	if *httpTimeout <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --http-timeout must be positive")
+		return exitUsage
+	}
+	if *interval < ui.MinRefreshInterval || *interval > ui.MaxRefreshInterval {
+		fmt.Fprintf(os.Stderr, "Error: --interval must be between %s and %s\n", ui.MinRefreshInterval, ui.MaxRefreshInterval)
+		return exitUsage
+	}
+	if *pid < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --pid must not be negative")
+		return exitUsage
+	}
+	if *maxRows < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-rows must not be negative")
+		return exitUsage
+	}
+	// Settings file values apply unless the flag was given explicitly or
+	// through the environment
+	prefs := config.LoadPreferences()
+	if !setFlags["max-events"] && prefs.MaxEvents != 0 {
+		*maxEvents = prefs.MaxEvents
+	}
+	if !setFlags["max-histories"] && prefs.MaxHistories != 0 {
+		*maxHistories = prefs.MaxHistories
+	}
+	if !setFlags["max-port-events"] && prefs.MaxPortEvents != 0 {
+		*maxPortEvents = prefs.MaxPortEvents
+	}
+	if *maxEvents < 1 || *maxHistories < 1 || *maxPortEvents < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --max-events, --max-histories and --max-port-events must be positive")
+		return exitUsage
+	}
+	keys := ui.DefaultKeyMap()
+	if err := keys.Override(prefs.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: settings.json: %v\n", err)
+		return exitUsage
+	}
+	if *stableScans < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --stable-scans must be at least 1")
+		return exitUsage
+	}
+	if err := export.SetNameTemplate(*exportName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	exportFormats, err := export.ParseFormats(*exportFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --export-format: %v\n", err)
+		return exitUsage
+	}
+	if *autoExportEvery < 0 || *autoExportKeep < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --auto-export must not be negative and --auto-export-keep must be at least 1")
+		return exitUsage
+	}
+	if *autoExportEvery > 0 {
+		if err := export.CheckAutoExportName(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		if err := os.MkdirAll(*autoExportDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create --auto-export-dir: %v\n", err)
+			return exitUsage
+		}
+	}
+	table, err := newTableOptions(*columns, *noColor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --columns: %v\n", err)
+		return exitUsage
+	}
+	sortColumn, secondarySort, err := ui.ParseSort(*sortBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --sort: %v\n", err)
+		return exitUsage
+	}
+	// The sort last used applies unless given on the command line. A
+	// saved sort gaze doesn't know is ignored.
+	if prefs.Sort != "" {
+		if column, secondary, err := ui.ParseSort(prefs.Sort); err == nil && !setFlags["sort"] {
+			sortColumn, secondarySort = column, secondary
+		}
+		if !setFlags["sort-desc"] {
+			*sortDesc = prefs.SortDescending
+		}
+	}
+	var expected baseline.Baseline
+	if *baselineFile != "" {
+		if expected, err = baseline.Load(*baselineFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+	}
+	var autoKillRule *ui.AutoKillRule
+	if *autoKill != "" {
+		switch {
+		case !*autoKillConfirm:
+			fmt.Fprintln(os.Stderr, "Error: --auto-kill kills processes without asking; add --auto-kill-confirm to enable it")
+			return exitUsage
+		case *readOnly:
+			fmt.Fprintln(os.Stderr, "Error: --auto-kill is disabled in read-only mode")
+			return exitUsage
+		}
+		rule, err := ui.ParseAutoKillRule(*autoKill)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --auto-kill: %v\n", err)
+			return exitUsage
+		}
+		autoKillRule = &rule
+	}
+	watchPorts, err := scanner.ParsePortList(*watch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --watch: %v\n", err)
+		return exitUsage
+	}
+	var alertLogFile *os.File
+	if *alertLog != "" {
+		if !*alertNew {
+			fmt.Fprintln(os.Stderr, "Error: --alert-log needs --alert-new")
+			return exitUsage
+		}
+		if alertLogFile, err = os.OpenFile(*alertLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --alert-log: %v\n", err)
+			return exitUsage
+		}
+		defer alertLogFile.Close()
+	}
+	shownRanges, err := scanner.ParsePortList(*portRange)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --range/--only-ports: %v\n", err)
+		return exitUsage
+	}
+	// --ignore-ports replaces the saved list of ignored ports, including
+	// those ignored with the ignore key
+	ignored := prefs.Ignored
+	if setFlags["ignore-ports"] {
+		if ignored, err = scanner.ParsePortList(*ignorePorts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --ignore-ports: %v\n", err)
+			return exitUsage
+		}
+	}
+	scanCfg := scanner.DefaultConfig()
+	scanCfg.HTTPTimeout = *httpTimeout
+	scanCfg.CollectThreads = *threads
+	scanCfg.IncludeUnix = *unixSockets
+	scanCfg.NoHTTPCheck = *noHTTPCheck
+	if *httpPorts != "" {
+		if scanCfg.HTTPPorts, err = scanner.ParsePortList(*httpPorts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --http-ports: %v\n", err)
+			return exitUsage
+		}
+	}
+	if *tlsPorts != "" {
+		if scanCfg.TLSPorts, err = scanner.ParsePortList(*tlsPorts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --tls-ports: %v\n", err)
+			return exitUsage
+		}
+	}
+	if *bindCIDR != "" {
+		if scanCfg.BindCIDR, err = scanner.ParseBindCIDR(*bindCIDR); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --bind-cidr: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	// Headless health check mode
+	if *checkPort != 0 || *checkHTTP != "" {
+		return runCheck(scanCfg, *checkPort, *checkHTTP, *verbose)
+	}
+
+	// Headless bulk kill mode
+	if *killRange != "" {
+		return runKillRange(scanCfg, *killRange, killRangeOptions{
+			readOnly: *readOnly,
+			dryRun:   *dryRun,
+			yes:      *yes,
+			force:    *force,
+		}, table)
+	}
+
+	// --json on its own is shorthand for a one-shot JSON export to stdout
+	if *asJSON {
+		if setFlags["format"] && *format != string(export.FormatJSON) {
+			fmt.Fprintf(os.Stderr, "Error: --json can't be combined with --format %s\n", *format)
+			return exitUsage
+		}
+		*once, *format = true, string(export.FormatJSON)
+	}
+
+	// Headless Prometheus textfile mode
+	if *prometheusFile != "" {
+		return runPrometheus(scanCfg, ignored, *prometheusFile, *interval, *once)
+	}
+
+	// Headless one-shot export mode
+	if *once {
+		return runOnce(scanCfg, ignored, *exportTarget, *format, table, expected)
+	}
+
+	// Only one instance may write the state files at a time
+	stateOwner := 0
+	lock, err := config.AcquireLock()
+	var locked *config.LockedError
+	switch {
+	case errors.As(err, &locked):
+		stateOwner = locked.PID
+	case err != nil:
+		slog.Warn("state lock unavailable", "error", err)
+	default:
+		defer lock.Release()
+	}
+	// --ignore-ports is remembered for later sessions, like ports ignored
+	// with the ignore key
+	if cliFlags["ignore-ports"] && stateOwner == 0 && !slices.Equal(ignored, prefs.Ignored) {
+		prefs.Ignored = ignored
+		if err := config.SavePreferences(prefs); err != nil {
+			slog.Warn("saving --ignore-ports failed", "error", err)
+		}
+	}
+	historyFile, err := config.HistoryPath()
+	if err != nil {
+		slog.Warn("history file unavailable", "error", err)
+	}
+
 	// Create the Bubble Tea program
-	p := tea.NewProgram(ui.InitialModel(), tea.WithAltScreen())
+	opts := ui.Options{
+		ReadOnly:    *readOnly,
+		DryRun:      *dryRun,
+		ScanConfig:  scanCfg,
+		StableScans: *stableScans,
+		AutoExport: ui.AutoExportOptions{
+			Interval: *autoExportEvery,
+			Dir:      *autoExportDir,
+			Keep:     *autoExportKeep,
+		},
+		StateOwner:     stateOwner,
+		ContainersOnly: *containersOnly,
+		PID:            int32(*pid),
+		PortRanges:     shownRanges,
+		Ignored:        ignored,
+		Baseline:       expected,
+		SortColumn:     sortColumn,
+		SecondarySort:  secondarySort,
+		SortDescending: *sortDesc,
+		HighlightNew:   *highlightNew,
+		MaxEvents:      *maxEvents,
+		MaxHistories:   *maxHistories,
+		MaxPortEvents:  *maxPortEvents,
+		KeyMap:         keys,
+		HistoryFile:    historyFile,
+		MaxRows:        *maxRows,
+		AutoKill:       autoKillRule,
+		Watch:          watchPorts,
+		AlertNew:       *alertNew,
+		Interval:       *interval,
+		ExportFormats:  exportFormats,
+		ExportDir:      *exportDir,
+	}
+	if alertLogFile != nil {
+		opts.AlertLog = alertLogFile
+	}
+	model := ui.InitialModel(opts)
+	if *serveAddr != "" {
+		if err := server.Start(*serveAddr, model.HistoryTracker(), scanCfg, ignored); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --serve: %v\n", err)
+			return exitUsage
+		}
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	// Run the program
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running gaze: %v\n", err)
-		os.Exit(1)
+		return exitFailed
+	}
+
+	// Keep the history for the next session and gaze --history, unless
+	// another instance owns the state files
+	if m, ok := final.(ui.Model); ok && historyFile != "" && stateOwner == 0 {
+		if err := m.SaveHistory(historyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailed
+		}
 	}
+	return exitOK
 }
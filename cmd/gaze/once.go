@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/junjiang/gaze/internal/baseline"
+	"github.com/junjiang/gaze/internal/export"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// tableFormat prints the snapshot as a plain-text table instead of exporting it
+const tableFormat = "table"
+
+// runOnce scans the ports a single time and exports the snapshot, without
+// the ignored ports, in the given format to target, a directory or "-"
+// for stdout. The table format is always printed to stdout. With a
+// baseline, ports it doesn't allow are reported on stderr and make the run
+// fail. It returns the process exit code.
+func runOnce(cfg scanner.Config, ignored []scanner.PortRange, target, format string, table tableOptions, expected baseline.Baseline) int {
+	exporter, ok := export.ExporterFor(export.ExportFormat(format))
+	switch {
+	case format == tableFormat:
+		if target != export.StdoutTarget {
+			fmt.Fprintln(os.Stderr, "Error: the table format can only be written to stdout")
+			return exitUsage
+		}
+	case !ok:
+		fmt.Fprintf(os.Stderr, "Error: unsupported export format %q (use json, csv, yaml, md, html or table)\n", format)
+		return exitUsage
+	}
+
+	ports, err := scanner.ScanPorts(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+	ports = scanner.WithoutPorts(ports, ignored)
+
+	if exporter == nil {
+		if err := table.write(ports); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailed
+		}
+	} else {
+		path, err := exporter(ports, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailed
+		}
+
+		// Only report the path when it doesn't pollute the exported stream
+		if target != export.StdoutTarget {
+			fmt.Fprintln(os.Stderr, path)
+		}
+	}
+
+	if expected == nil {
+		return exitOK
+	}
+	unexpected := expected.Unexpected(ports)
+	sort.Slice(unexpected, func(i, j int) bool { return unexpected[i].Port < unexpected[j].Port })
+	for _, p := range unexpected {
+		fmt.Fprintf(os.Stderr, "UNEXPECTED: port %d held by %s (PID %d)\n", p.Port, p.Process, p.PID)
+	}
+	if len(unexpected) > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
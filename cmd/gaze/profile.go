@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	_ "net/http/pprof" // Registers the /debug/pprof handlers
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// profileOptions selects gaze's self-profiling, all off by default
+type profileOptions struct {
+	pprofAddr  string // Serve net/http/pprof on this address
+	cpuProfile string // Write a CPU profile of the whole run to this file
+	memProfile string // Write a heap profile to this file on exit
+}
+
+// startProfiling starts the selected profiling and returns a function
+// that finishes it, writing any profile files
+func startProfiling(opts profileOptions) (stop func(), err error) {
+	var cpuFile *os.File
+	if opts.pprofAddr != "" {
+		// Listen before returning so a bad address is reported up front
+		ln, err := net.Listen("tcp", opts.pprofAddr)
+		if err != nil {
+			return nil, fmt.Errorf("--pprof-addr: %w", err)
+		}
+		slog.Info("serving pprof", "addr", ln.Addr().String())
+		go func() {
+			if err := http.Serve(ln, nil); err != nil {
+				slog.Warn("pprof server stopped", "error", err)
+			}
+		}()
+	}
+	if opts.cpuProfile != "" {
+		cpuFile, err = os.Create(opts.cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("--cpuprofile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, fmt.Errorf("--cpuprofile: %w", err)
+		}
+	}
+
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if opts.memProfile != "" {
+			if err := writeHeapProfile(opts.memProfile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --memprofile: %v\n", err)
+			}
+		}
+	}, nil
+}
+
+// writeHeapProfile writes a heap profile reflecting the latest GC
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
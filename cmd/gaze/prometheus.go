@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/junjiang/gaze/internal/export"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// runPrometheus scans the ports every interval and writes them, without
+// the ignored ports, to path as Prometheus metrics, until interrupted or,
+// with once set, after the first scan. A failed first write ends the run,
+// since later ones would fail the same way; after that, failures are
+// reported and the next scan retries. It returns the process exit code.
+func runPrometheus(cfg scanner.Config, ignored []scanner.PortRange, path string, interval time.Duration, once bool) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for written := false; ; written = true {
+		ports, err := scanner.ScanPorts(cfg)
+		if err == nil {
+			err = export.ToPrometheus(scanner.WithoutPorts(ports, ignored), path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if !written {
+				return exitFailed
+			}
+		}
+		if once {
+			return exitOK
+		}
+
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case <-time.After(interval):
+		}
+	}
+}
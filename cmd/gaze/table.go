@@ -0,0 +1,50 @@
+package main
+
+import (
+	"cmp"
+	"os"
+	"sort"
+
+	"github.com/junjiang/gaze/internal/render"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// tableOptions controls plain-text table output in the headless modes
+type tableOptions struct {
+	columns []render.Column
+	color   bool
+}
+
+// newTableOptions parses --columns and decides whether to use color, which
+// is only done when writing to a terminal and not disabled by --no-color
+// or the NO_COLOR convention
+func newTableOptions(columns string, noColor bool) (tableOptions, error) {
+	cols, err := render.ParseColumns(columns)
+	if err != nil {
+		return tableOptions{}, err
+	}
+	color := !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	return tableOptions{columns: cols, color: color}, nil
+}
+
+// write prints ports to stdout as a table ordered by port
+func (o tableOptions) write(ports []scanner.PortInfo) error {
+	sorted := append([]scanner.PortInfo(nil), ports...)
+	sort.Slice(sorted, func(i, j int) bool {
+		// Unix sockets have no port and follow the TCP and UDP ports by
+		// path. TCP comes before UDP on the same port, then by address.
+		a, b := sorted[i], sorted[j]
+		if (a.SocketType == scanner.SocketUnix) != (b.SocketType == scanner.SocketUnix) {
+			return b.SocketType == scanner.SocketUnix
+		}
+		return cmp.Or(cmp.Compare(a.Port, b.Port), cmp.Compare(a.SocketType, b.SocketType),
+			cmp.Compare(a.ListenAddr, b.ListenAddr), cmp.Compare(a.SocketPath, b.SocketPath)) < 0
+	})
+	return render.Table(os.Stdout, sorted, o.columns, render.Options{Color: o.color})
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Build information, set at build time via -ldflags "-X main.version=..."
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+// buildInfo describes this gaze binary
+type buildInfo struct {
+	Version      string          `json:"version"`
+	Commit       string          `json:"commit"`
+	GoVersion    string          `json:"go_version"`
+	OS           string          `json:"os"`
+	Arch         string          `json:"arch"`
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// currentBuildInfo collects the build and runtime details, with the
+// capabilities probed in this environment
+func currentBuildInfo(caps []scanner.Capability) buildInfo {
+	info := buildInfo{
+		Version:      version,
+		Commit:       commit,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Capabilities: make(map[string]bool),
+	}
+	for _, c := range caps {
+		info.Capabilities[c.Name] = c.Enabled
+	}
+	return info
+}
+
+// runVersion prints the build information, as JSON if asJSON is set. It
+// returns the process exit code.
+func runVersion(asJSON bool) int {
+	caps := scanner.ProbeCapabilities()
+	info := currentBuildInfo(caps)
+
+	if asJSON {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailed
+		}
+		fmt.Println(string(data))
+		return exitOK
+	}
+
+	fmt.Printf("gaze %s (commit %s)\n", info.Version, info.Commit)
+	fmt.Printf("%s %s/%s\n", info.GoVersion, info.OS, info.Arch)
+	for _, c := range caps {
+		mark := "yes"
+		if !c.Enabled {
+			mark = "no"
+		}
+		fmt.Printf("%s: %s (%s)\n", c.Name, mark, c.Detail)
+	}
+	return exitOK
+}
@@ -0,0 +1,69 @@
+// Package baseline checks scanned ports against a list of expected ones
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Entry is an expected listener. Process is optional; when set, the port
+// only matches if it is held by a process of that name.
+type Entry struct {
+	Port    int    `json:"port"`
+	Process string `json:"process,omitempty"`
+}
+
+// Baseline is the set of ports expected to be open
+type Baseline []Entry
+
+// Load reads a baseline from a JSON file containing a list of entries,
+// e.g. [{"port": 22, "process": "sshd"}, {"port": 5432}]
+func Load(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	for _, e := range b {
+		if e.Port < 1 || e.Port > 65535 {
+			return nil, fmt.Errorf("baseline %s: port %d out of range 1-65535", path, e.Port)
+		}
+	}
+	return b, nil
+}
+
+// Allows reports whether a port matches an entry in the baseline. Unix
+// sockets have no port to list, so the baseline doesn't judge them.
+func (b Baseline) Allows(p scanner.PortInfo) bool {
+	if p.SocketType == scanner.SocketUnix {
+		return true
+	}
+	for _, e := range b {
+		if e.Port != p.Port {
+			continue
+		}
+		if e.Process == "" || strings.EqualFold(e.Process, p.Process) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unexpected returns the ports not allowed by the baseline
+func (b Baseline) Unexpected(ports []scanner.PortInfo) []scanner.PortInfo {
+	var unexpected []scanner.PortInfo
+	for _, p := range ports {
+		if !b.Allows(p) {
+			unexpected = append(unexpected, p)
+		}
+	}
+	return unexpected
+}
@@ -0,0 +1,35 @@
+package baseline
+
+import (
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestUnexpected(t *testing.T) {
+	b := Baseline{{Port: 22, Process: "sshd"}, {Port: 5432}}
+
+	tests := []struct {
+		name string
+		port scanner.PortInfo
+		want bool // Whether the port is reported as unexpected
+	}{
+		{"listed port", scanner.PortInfo{Port: 5432, Process: "postgres", SocketType: scanner.SocketTCP}, false},
+		{"listed port and process", scanner.PortInfo{Port: 22, Process: "SSHD", SocketType: scanner.SocketTCP}, false},
+		{"listed port, other process", scanner.PortInfo{Port: 22, Process: "nc", SocketType: scanner.SocketTCP}, true},
+		{"unlisted port", scanner.PortInfo{Port: 8080, Process: "node", SocketType: scanner.SocketTCP}, true},
+		{"unix socket", scanner.PortInfo{Process: "dockerd", SocketType: scanner.SocketUnix, SocketPath: "/run/docker.sock"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := len(b.Unexpected([]scanner.PortInfo{tt.port})) == 1
+			if got != tt.want {
+				t.Errorf("unexpected = %v, want %v", got, tt.want)
+			}
+			if allowed := b.Allows(tt.port); allowed == tt.want {
+				t.Errorf("Allows = %v, want %v", allowed, !tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// LockedError is returned by AcquireLock when another running gaze holds
+// the state lock
+type LockedError struct {
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("gaze state is in use by another instance (PID %d)", e.PID)
+}
+
+// Lock is an advisory lock on gaze's state files, held by one instance at
+// a time so concurrent instances don't overwrite each other's state
+type Lock struct {
+	path string
+}
+
+// LockPath returns the location of the state lockfile
+func LockPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(dir, "gaze", "gaze.lock"), nil
+}
+
+// HistoryPath returns the location of the saved port history
+func HistoryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(dir, "gaze", "history.json"), nil
+}
+
+// AcquireLock takes the state lock by creating a lockfile holding this
+// process's PID. A lockfile left behind by a process that no longer runs
+// is taken over. If a live instance holds it, a *LockedError is returned.
+func AcquireLock() (*Lock, error) {
+	path, err := LockPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// A second attempt follows removal of a stale lockfile
+	for range 2 {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lockfile: %w", err)
+			}
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lockfile: %w", err)
+		}
+
+		if pid, ok := lockOwner(path); ok {
+			return nil, &LockedError{PID: pid}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lockfile: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("failed to acquire lockfile %s", path)
+}
+
+// Release removes the lockfile
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lockfile: %w", err)
+	}
+	return nil
+}
+
+// lockOwner returns the PID recorded in a lockfile and whether that
+// process is still running
+func lockOwner(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	alive, err := process.PidExists(int32(pid))
+	return pid, err == nil && alive
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Preferences holds view settings that persist between sessions
+type Preferences struct {
+	Compact bool  `json:"compact"`
+	Pinned  []int `json:"pinned,omitempty"` // Ports kept at the top of the table
+
+	// Ports hidden from the table, history and exports: those ignored with
+	// the ignore key and the last --ignore-ports list. Single ports are
+	// saved as numbers, ranges as strings such as "30000-40000".
+	Ignored []scanner.PortRange `json:"ignored,omitempty"`
+
+	// Last used sort and view, restored on the next launch. Sort is a
+	// --sort value, e.g. "process" or "process,memory"; View is "ports",
+	// "history", "stats" or "top".
+	Sort           string `json:"sort,omitempty"`
+	SortDescending bool   `json:"sort_descending,omitempty"`
+	View           string `json:"view,omitempty"`
+
+	// History capacities; zero means the built-in default. These are only
+	// read from the file, gaze never writes them.
+	MaxEvents     int `json:"max_events,omitempty"`
+	MaxHistories  int `json:"max_histories,omitempty"`
+	MaxPortEvents int `json:"max_port_events,omitempty"`
+
+	// Key binding overrides by action name, e.g. {"kill": ["ctrl+k"]}.
+	// Like the capacities, this is only read from the file.
+	Keys map[string][]string `json:"keys,omitempty"`
+}
+
+// PreferencesPath returns the location of the preferences file
+func PreferencesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(dir, "gaze", "settings.json"), nil
+}
+
+// LoadPreferences reads the saved preferences. A missing or corrupt file
+// yields the defaults rather than an error.
+func LoadPreferences() Preferences {
+	var prefs Preferences
+
+	path, err := PreferencesPath()
+	if err != nil {
+		return prefs
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return Preferences{}
+	}
+	return prefs
+}
+
+// SavePreferences writes the preferences to disk. The file is replaced
+// atomically, so a crash or a concurrent read never sees it half written.
+func SavePreferences(prefs Preferences) error {
+	path, err := PreferencesPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write preferences: %w", err)
+	}
+	return nil
+}
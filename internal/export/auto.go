@@ -0,0 +1,42 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// autoPrefix sets automatic snapshots apart from exports written on
+// request, so Prune only ever removes files gaze wrote on its own
+const autoPrefix = "auto-"
+
+// AutoExport writes a JSON and CSV snapshot of ports to dir for
+// --auto-export, then prunes older snapshots so that at most keep of each
+// format remain
+func AutoExport(ports []scanner.PortInfo, dir string, keep int) error {
+	snapshot := NewSnapshot(ports)
+	timestamp := snapshot.Timestamp
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	path := filepath.Join(dir, autoPrefix+exportFilename(FormatJSON, timestamp))
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	path = filepath.Join(dir, autoPrefix+exportFilename(FormatCSV, timestamp))
+	err = atomicfile.Write(path, func(w io.Writer) error {
+		return writeCSV(w, ports, timestamp)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+
+	return Prune(dir, keep)
+}
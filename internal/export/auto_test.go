@@ -0,0 +1,107 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		keep  int
+		want  []string
+	}{
+		{
+			name: "keeps newest of each format",
+			files: []string{
+				"auto-gaze-export-2024-01-01-00-00-01.json",
+				"auto-gaze-export-2024-01-01-00-00-02.json",
+				"auto-gaze-export-2024-01-01-00-00-03.json",
+				"auto-gaze-export-2024-01-01-00-00-01.csv",
+			},
+			keep: 2,
+			want: []string{
+				"auto-gaze-export-2024-01-01-00-00-01.csv",
+				"auto-gaze-export-2024-01-01-00-00-02.json",
+				"auto-gaze-export-2024-01-01-00-00-03.json",
+			},
+		},
+		{
+			name: "leaves manual exports",
+			files: []string{
+				"gaze-export-2024-01-01-00-00-00.json",
+				"auto-gaze-export-2024-01-01-00-00-01.json",
+				"auto-gaze-export-2024-01-01-00-00-02.json",
+				"gaze-export-2024-01-01-00-00-03.json",
+			},
+			keep: 1,
+			want: []string{
+				"auto-gaze-export-2024-01-01-00-00-02.json",
+				"gaze-export-2024-01-01-00-00-00.json",
+				"gaze-export-2024-01-01-00-00-03.json",
+			},
+		},
+		{
+			name: "leaves unrelated files",
+			files: []string{
+				"auto-gaze-export-2024-01-01-00-00-01.json",
+				"auto-gaze-export-2024-01-01-00-00-02.json",
+				"auto-notes.json",
+				"package.json",
+				"history-gaze-export-2024-01-01-00-00-00.json",
+			},
+			keep: 1,
+			want: []string{
+				"auto-gaze-export-2024-01-01-00-00-02.json",
+				"auto-notes.json",
+				"history-gaze-export-2024-01-01-00-00-00.json",
+				"package.json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := Prune(dir, tt.keep); err != nil {
+				t.Fatalf("Prune: %v", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("after Prune got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoExportSkipsManualExports(t *testing.T) {
+	dir := t.TempDir()
+	manual, err := ToJSON(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AutoExport(nil, dir, 1); err != nil {
+		t.Fatalf("AutoExport: %v", err)
+	}
+	if _, err := os.Stat(manual); err != nil {
+		t.Errorf("manual export was removed: %v", err)
+	}
+}
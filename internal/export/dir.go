@@ -0,0 +1,21 @@
+package export
+
+import (
+	"fmt"
+	"os"
+)
+
+// PrepareDir creates an export directory if needed and checks that files
+// can be written to it
+func PrepareDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	probe, err := os.CreateTemp(dir, ".gaze-probe*")
+	if err != nil {
+		return fmt.Errorf("export directory %s isn't writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
@@ -4,53 +4,114 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/junjiang/gaze/internal/atomicfile"
 	"github.com/junjiang/gaze/internal/scanner"
+	"gopkg.in/yaml.v3"
 )
 
 // ExportFormat represents the export file format
 type ExportFormat string
 
 const (
-	FormatJSON ExportFormat = "json"
-	FormatCSV  ExportFormat = "csv"
+	FormatJSON     ExportFormat = "json"
+	FormatCSV      ExportFormat = "csv"
+	FormatYAML     ExportFormat = "yaml"
+	FormatMarkdown ExportFormat = "md"
+	FormatHTML     ExportFormat = "html"
 )
 
+// Formats lists the export formats, in the order they're written
+var Formats = []ExportFormat{FormatJSON, FormatCSV, FormatYAML, FormatMarkdown, FormatHTML}
+
+// Exporter writes a snapshot of ports to outputDir and returns the path
+// written
+type Exporter func(ports []scanner.PortInfo, outputDir string) (string, error)
+
+// ExporterFor returns the exporter for a format
+func ExporterFor(format ExportFormat) (Exporter, bool) {
+	switch format {
+	case FormatJSON:
+		return ToJSON, true
+	case FormatCSV:
+		return ToCSV, true
+	case FormatYAML:
+		return ToYAML, true
+	case FormatMarkdown:
+		return ToMarkdown, true
+	case FormatHTML:
+		return func(ports []scanner.PortInfo, outputDir string) (string, error) {
+			return ToHTML(NewSnapshot(ports), outputDir)
+		}, true
+	}
+	return nil, false
+}
+
+// ParseFormats parses a comma-separated list of export formats, e.g.
+// "json,yaml"
+func ParseFormats(spec string) ([]ExportFormat, error) {
+	var formats []ExportFormat
+	for _, name := range strings.Split(spec, ",") {
+		format := ExportFormat(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := ExporterFor(format); !ok {
+			return nil, fmt.Errorf("unsupported export format %q (use json, csv, yaml, md or html)", name)
+		}
+		if !slices.Contains(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+	return formats, nil
+}
+
+// StdoutTarget is the output directory that sends an export to stdout
+const StdoutTarget = "-"
+
+// stdoutPath is the path reported for exports written to stdout
+const stdoutPath = "(stdout)"
+
 // ExportSnapshot represents a snapshot of ports at a specific time
 type ExportSnapshot struct {
-	Timestamp time.Time          `json:"timestamp"`
-	Ports     []scanner.PortInfo `json:"ports"`
-	Summary   ExportSummary      `json:"summary"`
+	Timestamp time.Time          `json:"timestamp" yaml:"timestamp"`
+	Ports     []scanner.PortInfo `json:"ports" yaml:"ports"`
+	Summary   ExportSummary      `json:"summary" yaml:"summary"`
 }
 
 // ExportSummary provides aggregate information
 type ExportSummary struct {
-	TotalPorts      int            `json:"total_ports"`
-	UniqueProcesses int            `json:"unique_processes"`
-	ProcessCounts   map[string]int `json:"process_counts"`
+	TotalPorts      int            `json:"total_ports" yaml:"total_ports"`
+	UniqueProcesses int            `json:"unique_processes" yaml:"unique_processes"`
+	ProcessCounts   map[string]int `json:"process_counts" yaml:"process_counts"`
 }
 
-// ToJSON exports the port data to a JSON file
+// ToJSON exports the port data to a JSON file. An outputDir of "-"
+// writes to stdout instead.
 func ToJSON(ports []scanner.PortInfo, outputDir string) (string, error) {
-	timestamp := time.Now()
-	filename := fmt.Sprintf("gaze-export-%s.json", timestamp.Format("2006-01-02-15-04-05"))
-	filepath := filepath.Join(outputDir, filename)
-
-	snapshot := ExportSnapshot{
-		Timestamp: timestamp,
-		Ports:     ports,
-		Summary:   generateSummary(ports),
-	}
+	snapshot := NewSnapshot(ports)
+	timestamp := snapshot.Timestamp
 
 	data, err := json.MarshalIndent(snapshot, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	err = os.WriteFile(filepath, data, 0644)
+	if outputDir == StdoutTarget {
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			return "", fmt.Errorf("failed to write JSON to stdout: %w", err)
+		}
+		return stdoutPath, nil
+	}
+
+	filename := exportFilename(FormatJSON, timestamp)
+	filepath := filepath.Join(outputDir, filename)
+
+	err = atomicfile.WriteFile(filepath, data)
 	if err != nil {
 		return "", fmt.Errorf("failed to write JSON file: %w", err)
 	}
@@ -58,25 +119,68 @@ func ToJSON(ports []scanner.PortInfo, outputDir string) (string, error) {
 	return filepath, nil
 }
 
-// ToCSV exports the port data to a CSV file
+// ToYAML exports the port data to a YAML file, with the same structure as
+// ToJSON. An outputDir of "-" writes to stdout instead.
+func ToYAML(ports []scanner.PortInfo, outputDir string) (string, error) {
+	snapshot := NewSnapshot(ports)
+	timestamp := snapshot.Timestamp
+
+	data, err := yaml.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	if outputDir == StdoutTarget {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return "", fmt.Errorf("failed to write YAML to stdout: %w", err)
+		}
+		return stdoutPath, nil
+	}
+
+	filename := exportFilename(FormatYAML, timestamp)
+	filepath := filepath.Join(outputDir, filename)
+
+	err = atomicfile.WriteFile(filepath, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to write YAML file: %w", err)
+	}
+
+	return filepath, nil
+}
+
+// ToCSV exports the port data to a CSV file. An outputDir of "-" writes
+// to stdout instead.
 func ToCSV(ports []scanner.PortInfo, outputDir string) (string, error) {
 	timestamp := time.Now()
-	filename := fmt.Sprintf("gaze-export-%s.csv", timestamp.Format("2006-01-02-15-04-05"))
+
+	if outputDir == StdoutTarget {
+		if err := writeCSV(os.Stdout, ports, timestamp); err != nil {
+			return "", err
+		}
+		return stdoutPath, nil
+	}
+
+	filename := exportFilename(FormatCSV, timestamp)
 	filepath := filepath.Join(outputDir, filename)
 
-	file, err := os.Create(filepath)
+	err := atomicfile.Write(filepath, func(w io.Writer) error {
+		return writeCSV(w, ports, timestamp)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create CSV file: %w", err)
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	return filepath, nil
+}
+
+// writeCSV writes the port data as CSV
+func writeCSV(w io.Writer, ports []scanner.PortInfo, timestamp time.Time) error {
+	writer := csv.NewWriter(w)
 
 	// Write header
-	header := []string{"Port", "PID", "Process", "Status", "Timestamp"}
+	header := []string{"Port", "PID", "Process", "Status", "Timestamp", "Type", "Path", "Service", "Address"}
 	if err := writer.Write(header); err != nil {
-		return "", fmt.Errorf("failed to write CSV header: %w", err)
+		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
 	// Write data
@@ -88,13 +192,54 @@ func ToCSV(ports []scanner.PortInfo, outputDir string) (string, error) {
 			p.Process,
 			p.Status,
 			timestampStr,
+			p.SocketType,
+			p.SocketPath,
+			p.WellKnown,
+			p.ListenAddr,
 		}
 		if err := writer.Write(record); err != nil {
-			return "", fmt.Errorf("failed to write CSV record: %w", err)
+			return fmt.Errorf("failed to write CSV record: %w", err)
 		}
 	}
 
-	return filepath, nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the oldest automatic snapshots in dir so that at most keep
+// files of each format remain. Exports written on request are never
+// removed. Export filenames embed their timestamp, so name order is age
+// order for a given template.
+func Prune(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	for _, format := range Formats {
+		pattern := exportPattern(format)
+		var matches []string
+		for _, entry := range entries {
+			name, auto := strings.CutPrefix(entry.Name(), autoPrefix)
+			if auto && entry.Type().IsRegular() && pattern.MatchString(name) {
+				matches = append(matches, entry.Name())
+			}
+		}
+		if len(matches) <= keep {
+			continue
+		}
+
+		sort.Strings(matches)
+		for _, name := range matches[:len(matches)-keep] {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return fmt.Errorf("failed to remove old export: %w", err)
+			}
+		}
+	}
+	return nil
 }
 
 // generateSummary creates a summary of the port data
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultNameTemplate is the filename template used unless overridden
+const DefaultNameTemplate = "gaze-export-{timestamp}.{format}"
+
+// timestampLayout is how {timestamp} is rendered in filenames
+const timestampLayout = "2006-01-02-15-04-05"
+
+// nameTemplate is the filename template for exports written to a directory
+var nameTemplate = DefaultNameTemplate
+
+// SetNameTemplate sets the filename template for exports. It supports the
+// placeholders {timestamp}, {host} and {format}; if {format} is missing the
+// format is appended as the extension so JSON and CSV exports don't collide.
+// Without {timestamp} each export replaces the last, for tools that expect a
+// fixed name. Templates that could place the file outside the output
+// directory are rejected.
+func SetNameTemplate(template string) error {
+	if err := validateNameTemplate(template); err != nil {
+		return err
+	}
+	nameTemplate = template
+	return nil
+}
+
+// validateNameTemplate checks that a template yields a plain filename
+func validateNameTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("export name template is empty")
+	}
+	if strings.ContainsAny(template, `/\`) || strings.Contains(template, "..") {
+		return fmt.Errorf("export name template %q must not contain path separators or \"..\"", template)
+	}
+	return nil
+}
+
+// CheckAutoExportName reports whether the name template suits
+// --auto-export, whose snapshots need {timestamp} to be kept apart and
+// pruned oldest first
+func CheckAutoExportName() error {
+	if !strings.Contains(nameTemplate, "{timestamp}") {
+		return fmt.Errorf("export name template %q must contain {timestamp} for --auto-export", nameTemplate)
+	}
+	return nil
+}
+
+// hostname is the host name exports are labelled with
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "localhost"
+	}
+	return host
+}
+
+// exportFilename renders the filename template for an export
+func exportFilename(format ExportFormat, t time.Time) string {
+	name := strings.NewReplacer(
+		"{timestamp}", t.Format(timestampLayout),
+		"{host}", hostname(),
+		"{format}", string(format),
+	).Replace(nameTemplate)
+
+	if !strings.Contains(nameTemplate, "{format}") {
+		name += "." + string(format)
+	}
+	// Hostnames are the only outside input; keep the result a plain filename
+	return filepath.Base(name)
+}
+
+// timestampPattern matches a {timestamp} rendered with timestampLayout
+const timestampPattern = `\d{4}-\d{2}-\d{2}-\d{2}-\d{2}-\d{2}`
+
+// exportPattern returns a regexp matching exactly the filenames that
+// exportFilename gives exports of format on this host. Everything but the
+// timestamp is matched literally, so files that merely share an extension
+// never match.
+func exportPattern(format ExportFormat) *regexp.Regexp {
+	var pattern strings.Builder
+	rest := nameTemplate
+	if !strings.Contains(rest, "{format}") {
+		rest += ".{format}"
+	}
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		pattern.WriteString(regexp.QuoteMeta(rest[:start]))
+		rest = rest[start:]
+
+		switch {
+		case strings.HasPrefix(rest, "{timestamp}"):
+			pattern.WriteString(timestampPattern)
+			rest = strings.TrimPrefix(rest, "{timestamp}")
+		case strings.HasPrefix(rest, "{host}"):
+			pattern.WriteString(regexp.QuoteMeta(hostname()))
+			rest = strings.TrimPrefix(rest, "{host}")
+		case strings.HasPrefix(rest, "{format}"):
+			pattern.WriteString(regexp.QuoteMeta(string(format)))
+			rest = strings.TrimPrefix(rest, "{format}")
+		default:
+			pattern.WriteString(regexp.QuoteMeta("{"))
+			rest = rest[1:]
+		}
+	}
+	return regexp.MustCompile("^" + pattern.String() + "$")
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateNameTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{"default", DefaultNameTemplate, false},
+		{"host and format", "ports-{host}-{timestamp}.{format}", false},
+		{"no format", "ports-{timestamp}", false},
+		{"empty", "  ", true},
+		{"separator", "out/{timestamp}.{format}", true},
+		{"parent", "..{timestamp}", true},
+		{"fixed name", "ports.{format}", false},
+		{"fixed name with host", "ports-{host}.{format}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNameTemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateNameTemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckAutoExportName(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{DefaultNameTemplate, false},
+		{"ports-{host}-{timestamp}.{format}", false},
+		{"ports.{format}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			defer SetNameTemplate(DefaultNameTemplate)
+			if err := SetNameTemplate(tt.template); err != nil {
+				t.Fatalf("SetNameTemplate(%q): %v", tt.template, err)
+			}
+			if err := CheckAutoExportName(); (err != nil) != tt.wantErr {
+				t.Errorf("CheckAutoExportName() with %q error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExportPattern(t *testing.T) {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	host := hostname()
+
+	tests := []struct {
+		name     string
+		template string
+		format   ExportFormat
+		file     string
+		want     bool
+	}{
+		{"own export", DefaultNameTemplate, FormatJSON, "gaze-export-2024-05-06-07-08-09.json", true},
+		{"other format", DefaultNameTemplate, FormatJSON, "gaze-export-2024-05-06-07-08-09.csv", false},
+		{"unrelated file", DefaultNameTemplate, FormatJSON, "package.json", false},
+		{"prefixed", DefaultNameTemplate, FormatJSON, "history-gaze-export-2024-05-06-07-08-09.json", false},
+		{"not a timestamp", DefaultNameTemplate, FormatJSON, "gaze-export-backup.json", false},
+		{"bare extension", "{timestamp}", FormatCSV, "2024-05-06-07-08-09.csv", true},
+		{"bare unrelated", "{timestamp}", FormatCSV, "data.csv", false},
+		{"metacharacters", "[ports]+{timestamp}.{format}", FormatJSON, "[ports]+2024-05-06-07-08-09.json", true},
+		{"metacharacters literal", "[ports]+{timestamp}.{format}", FormatJSON, "p2024-05-06-07-08-09.json", false},
+		{"host", "{host}-{timestamp}.{format}", FormatJSON, host + "-2024-05-06-07-08-09.json", true},
+		{"other host", "{host}-{timestamp}.{format}", FormatJSON, "x" + host + "-2024-05-06-07-08-09.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer SetNameTemplate(DefaultNameTemplate)
+			if err := SetNameTemplate(tt.template); err != nil {
+				t.Fatalf("SetNameTemplate(%q): %v", tt.template, err)
+			}
+
+			pattern := exportPattern(tt.format)
+			if got := pattern.MatchString(tt.file); got != tt.want {
+				t.Errorf("exportPattern(%q) matches %q = %v, want %v", tt.format, tt.file, got, tt.want)
+			}
+			if name := exportFilename(tt.format, at); !pattern.MatchString(name) {
+				t.Errorf("exportPattern(%q) doesn't match its own export %q", tt.format, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/history"
+)
+
+// historyPrefix sets history exports apart from port snapshots, so Prune
+// never mistakes one for the other
+const historyPrefix = "history-"
+
+// HistoryToJSON exports every tracked port's lifecycle, with its events,
+// and the tracker's event log to a JSON file
+func HistoryToJSON(tracker *history.Tracker, outputDir string) (string, error) {
+	snap := tracker.Snapshot()
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal history JSON: %w", err)
+	}
+
+	path := filepath.Join(outputDir, historyPrefix+exportFilename(FormatJSON, snap.SavedAt))
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write history JSON file: %w", err)
+	}
+	return path, nil
+}
+
+// HistoryToCSV exports every tracked port's lifecycle to a CSV file, one
+// row per event. Ports without recorded events get a single row.
+func HistoryToCSV(tracker *history.Tracker, outputDir string) (string, error) {
+	snap := tracker.Snapshot()
+
+	path := filepath.Join(outputDir, historyPrefix+exportFilename(FormatCSV, snap.SavedAt))
+	err := atomicfile.Write(path, func(w io.Writer) error {
+		return writeHistoryCSV(w, snap.Histories)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write history CSV file: %w", err)
+	}
+	return path, nil
+}
+
+// writeHistoryCSV writes port histories as CSV
+func writeHistoryCSV(w io.Writer, histories []*history.PortHistory) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Port", "Protocol", "PID", "Process", "FirstSeen", "LastSeen", "OpenCount", "IsActive",
+		"Event", "EventTime", "EventPID", "EventProcess"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, h := range histories {
+		port := []string{
+			fmt.Sprintf("%d", h.Port),
+			h.Protocol,
+			fmt.Sprintf("%d", h.PID),
+			h.Process,
+			h.FirstSeen.Format(time.RFC3339),
+			h.LastSeen.Format(time.RFC3339),
+			fmt.Sprintf("%d", h.OpenCount),
+			fmt.Sprintf("%t", h.IsActive),
+		}
+		if len(h.Events) == 0 {
+			if err := writer.Write(append(port, "", "", "", "")); err != nil {
+				return fmt.Errorf("failed to write CSV record: %w", err)
+			}
+			continue
+		}
+		for _, e := range h.Events {
+			record := slices.Concat(port, []string{
+				string(e.EventType),
+				e.Timestamp.Format(time.RFC3339),
+				fmt.Sprintf("%d", e.PID),
+				e.Process,
+			})
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV record: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
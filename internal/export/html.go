@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+// reportTemplate renders HTML reports. html/template escapes every value
+// for its context, so process names and command lines can't inject markup
+// or script.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"httpClass": httpClass,
+	"latency": func(d time.Duration) string {
+		if d <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%d", d.Milliseconds())
+	},
+}).Parse(reportTemplateText))
+
+// reportData is what the report template renders
+type reportData struct {
+	Host     string
+	Snapshot ExportSnapshot
+}
+
+// httpClass picks the style of an HTTP status cell, matching the colors
+// of the table in the UI
+func httpClass(code int) string {
+	switch {
+	case code >= 200 && code < 300:
+		return "http-ok"
+	case code >= 300 && code < 400:
+		return "http-redirect"
+	case code >= 400:
+		return "http-error"
+	}
+	return ""
+}
+
+// NewSnapshot takes a snapshot of ports as of now, with their summary
+func NewSnapshot(ports []scanner.PortInfo) ExportSnapshot {
+	return ExportSnapshot{
+		Timestamp: time.Now(),
+		Ports:     ports,
+		Summary:   generateSummary(ports),
+	}
+}
+
+// ToHTML exports a snapshot as a self-contained HTML report: a table that
+// sorts by any column when its header is clicked, the summary counts, and
+// HTTP statuses colored by class. It needs nothing but a browser to view.
+// An outputDir of "-" writes to stdout instead.
+func ToHTML(snapshot ExportSnapshot, outputDir string) (string, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, reportData{Host: hostname(), Snapshot: snapshot}); err != nil {
+		return "", fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	if outputDir == StdoutTarget {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return "", fmt.Errorf("failed to write HTML to stdout: %w", err)
+		}
+		return stdoutPath, nil
+	}
+
+	path := filepath.Join(outputDir, exportFilename(FormatHTML, snapshot.Timestamp))
+	if err := atomicfile.WriteFile(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write HTML file: %w", err)
+	}
+	return path, nil
+}
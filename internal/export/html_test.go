@@ -0,0 +1,40 @@
+package export
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestToHTMLEscapes(t *testing.T) {
+	const payload = "<script>alert(1)</script>"
+
+	snapshot := NewSnapshot([]scanner.PortInfo{{
+		Port:       8080,
+		SocketType: scanner.SocketTCP,
+		PID:        1234,
+		Process:    payload,
+		Cmdline:    payload + " --port 8080",
+	}})
+
+	path, err := ToHTML(snapshot, t.TempDir())
+	if err != nil {
+		t.Fatalf("ToHTML() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+
+	if strings.Contains(html, payload) {
+		t.Error("report contains the unescaped process name")
+	}
+	// Once in the process column, once in the command and once in the
+	// summary's process counts
+	if got := strings.Count(html, "&lt;script&gt;alert(1)&lt;/script&gt;"); got != 3 {
+		t.Errorf("escaped payload appears %d times, want 3", got)
+	}
+}
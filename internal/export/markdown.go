@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// markdownColumn is a column of the Markdown table
+type markdownColumn struct {
+	title string
+	right bool // Right-align, for numbers
+	value func(p scanner.PortInfo) string
+}
+
+// markdownColumns are the columns of Markdown exports, in order
+var markdownColumns = []markdownColumn{
+	{"Port", true, func(p scanner.PortInfo) string { return p.Endpoint() }},
+	{"PID", true, func(p scanner.PortInfo) string { return fmt.Sprintf("%d", p.PID) }},
+	{"Process", false, func(p scanner.PortInfo) string { return p.Process }},
+	{"Status", false, func(p scanner.PortInfo) string { return p.Status }},
+	{"CPU%", true, func(p scanner.PortInfo) string { return fmt.Sprintf("%.1f", p.CPUPercent) }},
+	{"Mem (MB)", true, func(p scanner.PortInfo) string { return fmt.Sprintf("%.1f", p.MemoryMB) }},
+}
+
+// ToMarkdown exports the port data as a GitHub-flavored Markdown table
+// followed by a summary line, for pasting into issues and tickets. An
+// outputDir of "-" writes to stdout instead.
+func ToMarkdown(ports []scanner.PortInfo, outputDir string) (string, error) {
+	timestamp := time.Now()
+
+	var buf bytes.Buffer
+	writeMarkdown(&buf, ports, timestamp)
+
+	if outputDir == StdoutTarget {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return "", fmt.Errorf("failed to write Markdown to stdout: %w", err)
+		}
+		return stdoutPath, nil
+	}
+
+	path := filepath.Join(outputDir, exportFilename(FormatMarkdown, timestamp))
+	if err := atomicfile.WriteFile(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write Markdown file: %w", err)
+	}
+	return path, nil
+}
+
+// writeMarkdown writes the port data as a Markdown table. Cells are padded
+// so the table also lines up as plain text.
+func writeMarkdown(w io.Writer, ports []scanner.PortInfo, timestamp time.Time) {
+	cells := make([][]string, len(ports))
+	widths := make([]int, len(markdownColumns))
+	for i, c := range markdownColumns {
+		widths[i] = max(utf8.RuneCountInString(c.title), 3)
+	}
+	for row, p := range ports {
+		cells[row] = make([]string, len(markdownColumns))
+		for i, c := range markdownColumns {
+			cells[row][i] = escapeMarkdown(c.value(p))
+			widths[i] = max(widths[i], utf8.RuneCountInString(cells[row][i]))
+		}
+	}
+
+	header := make([]string, len(markdownColumns))
+	rule := make([]string, len(markdownColumns))
+	for i, c := range markdownColumns {
+		header[i] = padMarkdown(c.title, widths[i], c.right)
+		rule[i] = strings.Repeat("-", widths[i])
+		if c.right {
+			rule[i] = rule[i][1:] + ":"
+		}
+	}
+	writeMarkdownRow(w, header)
+	writeMarkdownRow(w, rule)
+	for _, row := range cells {
+		for i, c := range markdownColumns {
+			row[i] = padMarkdown(row[i], widths[i], c.right)
+		}
+		writeMarkdownRow(w, row)
+	}
+
+	summary := generateSummary(ports)
+	fmt.Fprintf(w, "\n%d ports held by %d processes, as of %s.\n",
+		summary.TotalPorts, summary.UniqueProcesses, timestamp.Format("2006-01-02 15:04:05 MST"))
+}
+
+// writeMarkdownRow writes one table row
+func writeMarkdownRow(w io.Writer, cells []string) {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+}
+
+// padMarkdown pads a cell to width, on the left for right-aligned columns
+func padMarkdown(s string, width int, right bool) string {
+	pad := strings.Repeat(" ", width-utf8.RuneCountInString(s))
+	if right {
+		return pad + s
+	}
+	return s + pad
+}
+
+// escapeMarkdown keeps a value inside its table cell: pipes would start
+// a new cell and newlines a new row
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// prometheusMetric is a per-port gauge in the textfile output. Value
+// reports false for ports the metric doesn't apply to.
+type prometheusMetric struct {
+	name  string
+	help  string
+	value func(p scanner.PortInfo) (float64, bool)
+}
+
+// prometheusMetrics are written in this order, each with one sample per
+// TCP or UDP socket. The address label keeps a port bound on both IPv4
+// and IPv6 from yielding duplicate series.
+var prometheusMetrics = []prometheusMetric{
+	{"gaze_port_up", "Whether the port is listening (always 1; closed ports are absent).",
+		func(p scanner.PortInfo) (float64, bool) { return 1, true }},
+	{"gaze_port_cpu_percent", "CPU usage of the process holding the port.",
+		func(p scanner.PortInfo) (float64, bool) { return p.CPUPercent, true }},
+	{"gaze_port_memory_mb", "Memory usage in MB of the process holding the port.",
+		func(p scanner.PortInfo) (float64, bool) { return p.MemoryMB, true }},
+	{"gaze_port_http_status", "HTTP status code of the port's health check, for checked ports.",
+		func(p scanner.PortInfo) (float64, bool) { return float64(p.HTTPStatus), p.HTTPStatus > 0 }},
+}
+
+// ToPrometheus writes the ports as metrics in the Prometheus text format
+// to path, for node_exporter's textfile collector. The file is replaced
+// atomically so the collector never reads a partial one. Unix sockets have
+// no port and are left out.
+func ToPrometheus(ports []scanner.PortInfo, path string) error {
+	var buf bytes.Buffer
+	for _, metric := range prometheusMetrics {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&buf, "# TYPE %s gauge\n", metric.name)
+		for _, p := range ports {
+			if p.SocketType == scanner.SocketUnix {
+				continue
+			}
+			value, ok := metric.value(p)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&buf, "%s{port=\"%d\",protocol=\"%s\",address=\"%s\",process=\"%s\",pid=\"%d\"} %g\n",
+				metric.name, p.Port, p.SocketType, p.ListenAddr, escapeLabel(p.Process), p.PID, value)
+		}
+	}
+
+	if err := atomicfile.WriteFile(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write Prometheus file: %w", err)
+	}
+	return nil
+}
+
+// escapeLabel escapes a Prometheus label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
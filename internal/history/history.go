@@ -2,7 +2,9 @@ package history
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/junjiang/gaze/internal/scanner"
@@ -10,11 +12,12 @@ import (
 
 // PortEvent represents a port state change event
 type PortEvent struct {
-	Port      int
-	PID       int32
-	Process   string
-	EventType EventType
-	Timestamp time.Time
+	Port      int       `json:"port"`
+	Protocol  string    `json:"protocol"` // scanner.SocketTCP or scanner.SocketUDP
+	PID       int32     `json:"pid"`
+	Process   string    `json:"process"`
+	EventType EventType `json:"event_type"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // EventType represents the type of port event
@@ -27,142 +30,407 @@ const (
 
 // PortHistory tracks a port's lifecycle
 type PortHistory struct {
-	Port      int
-	PID       int32
-	Process   string
-	FirstSeen time.Time
-	LastSeen  time.Time
-	IsActive  bool
-	OpenCount int
-	Events    []PortEvent
+	Port      int         `json:"port"`
+	Protocol  string      `json:"protocol"`
+	PID       int32       `json:"pid"`
+	Process   string      `json:"process"`
+	FirstSeen time.Time   `json:"first_seen"`
+	LastSeen  time.Time   `json:"last_seen"`
+	IsActive  bool        `json:"is_active"`
+	OpenCount int         `json:"open_count"`
+	Events    []PortEvent `json:"events"`
+
+	// The most recent outage of a port that has reopened: when it was last
+	// seen before closing and how long it stayed closed. Zero until the
+	// port has reopened at least once.
+	LastDownStart    time.Time     `json:"last_down_start,omitzero"`
+	LastDownDuration time.Duration `json:"last_down_duration,omitzero"`
+
+	// Resource usage from recent scans, read with Tracker.GetSamples. Not
+	// saved, as it only describes the current session.
+	samples sampleRing
 }
 
-// Tracker manages port history tracking
+// PortKey identifies a tracked port. The same port number over TCP and
+// UDP is tracked as two ports.
+type PortKey struct {
+	Protocol string
+	Port     int
+}
+
+// KeyOf returns the key a scanned port is tracked under
+func KeyOf(p scanner.PortInfo) PortKey {
+	return PortKey{Protocol: p.SocketType, Port: p.Port}
+}
+
+// Key returns the key of the port the event happened on
+func (e PortEvent) Key() PortKey {
+	return PortKey{Protocol: e.Protocol, Port: e.Port}
+}
+
+// Key returns the key the history is tracked under
+func (h *PortHistory) Key() PortKey {
+	return PortKey{Protocol: h.Protocol, Port: h.Port}
+}
+
+// String formats the key for display: "8080" for TCP, the usual case,
+// and e.g. "53/udp" otherwise
+func (k PortKey) String() string {
+	if k.Protocol == scanner.SocketTCP || k.Protocol == "" {
+		return fmt.Sprintf("%d", k.Port)
+	}
+	return fmt.Sprintf("%d/%s", k.Port, k.Protocol)
+}
+
+// Flap detection: a port is flapping when it changes state at least
+// flapThreshold times within flapWindow
+const (
+	flapWindow    = 5 * time.Minute
+	flapThreshold = 4
+)
+
+// Default tracker capacities
+const (
+	DefaultMaxEvents     = 1000
+	DefaultMaxHistories  = 500
+	DefaultMaxPortEvents = 100
+)
+
+// Tracker manages port history tracking.
+//
+// The Tracker only sees what each scan samples: a port that opens and
+// closes between two Updates is never recorded, and flapping faster than
+// the scan interval shows up as fewer transitions than really happened.
+// Events are recorded per state transition, so repeated Updates with the
+// same state never emit duplicate events.
+//
+// A Tracker is safe for concurrent use. Histories and events are returned
+// as copies, so callers can't change what the tracker holds.
 type Tracker struct {
-	history      map[int]*PortHistory // Key: port number
-	events       []PortEvent
-	maxEvents    int
-	maxHistories int
+	mu sync.RWMutex // Guards everything below
+
+	history       map[PortKey]*PortHistory
+	events        []PortEvent
+	maxEvents     int
+	maxHistories  int
+	maxPortEvents int // Cap on each PortHistory's own event list
+	maxSamples    int // Capacity of each PortHistory's sample ring
+
+	// Staging for ports that haven't yet been seen for stableScans
+	// consecutive scans. Ports that vanish before then are only counted.
+	stableScans    int
+	pending        map[PortKey]*pendingPort
+	transientCount int
+
+	subscribers []func(PortEvent)
 }
 
-// NewTracker creates a new history tracker
-func NewTracker(maxEvents, maxHistories int) *Tracker {
+// pendingPort is a newly seen port waiting to be promoted to a history
+type pendingPort struct {
+	info      scanner.PortInfo
+	firstSeen time.Time
+	scans     int
+}
+
+// NewTracker creates a new history tracker. Each port keeps up to
+// maxSamples resource samples, so memory use stays bounded however long
+// gaze runs.
+func NewTracker(maxEvents, maxHistories, maxPortEvents, maxSamples int) *Tracker {
 	return &Tracker{
-		history:      make(map[int]*PortHistory),
-		events:       make([]PortEvent, 0),
-		maxEvents:    maxEvents,
-		maxHistories: maxHistories,
+		history:       make(map[PortKey]*PortHistory),
+		events:        make([]PortEvent, 0),
+		maxEvents:     maxEvents,
+		maxHistories:  maxHistories,
+		maxPortEvents: maxPortEvents,
+		maxSamples:    maxSamples,
+		stableScans:   1,
+		pending:       make(map[PortKey]*pendingPort),
 	}
 }
 
+// SetStableThreshold sets how many consecutive scans a new port must be
+// seen in before it is tracked. Values below 1 are treated as 1, which
+// tracks ports as soon as they appear.
+func (t *Tracker) SetStableThreshold(scans int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stableScans = max(scans, 1)
+}
+
 // Update processes a new scan and tracks changes
 func (t *Tracker) Update(currentPorts []scanner.PortInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	now := time.Now()
-	currentPortMap := make(map[int]scanner.PortInfo)
+	currentPortMap := make(map[PortKey]scanner.PortInfo)
 
-	// Build map of current ports
+	// Build map of current ports. Unix sockets have no port to track.
 	for _, p := range currentPorts {
-		currentPortMap[p.Port] = p
+		if p.SocketType == scanner.SocketUnix {
+			continue
+		}
+		currentPortMap[KeyOf(p)] = p
 	}
 
 	// Check for newly opened ports
-	for port, info := range currentPortMap {
-		if h, exists := t.history[port]; exists {
-			// Port still active, update last seen
-			h.LastSeen = now
+	for key, info := range currentPortMap {
+		if h, exists := t.history[key]; exists {
 			if !h.IsActive {
-				// Port was closed but now reopened
+				// Port was closed but now reopened. It has been down since
+				// it was last seen.
+				h.LastDownStart = h.LastSeen
+				h.LastDownDuration = now.Sub(h.LastSeen)
 				h.IsActive = true
 				h.OpenCount++
 				event := PortEvent{
-					Port:      port,
+					Port:      key.Port,
+					Protocol:  key.Protocol,
 					PID:       info.PID,
 					Process:   info.Process,
 					EventType: EventPortOpened,
 					Timestamp: now,
 				}
-				h.Events = append(h.Events, event)
-				t.addEvent(event)
+				t.recordEvent(h, event)
 			}
+			// Port still active, update last seen
+			h.LastSeen = now
+			h.samples.add(sampleOf(info, now), t.maxSamples)
 		} else {
-			// New port detected
+			// New port detected, stage it until it has proven stable
+			pending, staged := t.pending[key]
+			if !staged {
+				pending = &pendingPort{info: info, firstSeen: now}
+				t.pending[key] = pending
+			}
+			pending.info = info
+			pending.scans++
+			if pending.scans < t.stableScans {
+				continue
+			}
+			delete(t.pending, key)
+
 			h := &PortHistory{
-				Port:      port,
+				Port:      key.Port,
+				Protocol:  key.Protocol,
 				PID:       info.PID,
 				Process:   info.Process,
-				FirstSeen: now,
+				FirstSeen: pending.firstSeen,
 				LastSeen:  now,
 				IsActive:  true,
 				OpenCount: 1,
 				Events:    []PortEvent{},
 			}
 			event := PortEvent{
-				Port:      port,
+				Port:      key.Port,
+				Protocol:  key.Protocol,
 				PID:       info.PID,
 				Process:   info.Process,
 				EventType: EventPortOpened,
+				// Stamped now rather than firstSeen, so the event log stays
+				// in order behind events recorded while the port was staged
 				Timestamp: now,
 			}
-			h.Events = append(h.Events, event)
-			t.history[port] = h
-			t.addEvent(event)
+			h.samples.add(sampleOf(info, now), t.maxSamples)
+			t.history[key] = h
+			t.recordEvent(h, event)
+		}
+	}
+
+	// Staged ports that disappeared were transient
+	for key := range t.pending {
+		if _, stillActive := currentPortMap[key]; !stillActive {
+			delete(t.pending, key)
+			t.transientCount++
 		}
 	}
 
 	// Check for closed ports
-	for port, h := range t.history {
+	for key, h := range t.history {
 		if h.IsActive {
-			if _, stillActive := currentPortMap[port]; !stillActive {
+			if _, stillActive := currentPortMap[key]; !stillActive {
 				// Port has closed
 				h.IsActive = false
 				h.LastSeen = now
 				event := PortEvent{
-					Port:      port,
+					Port:      key.Port,
+					Protocol:  key.Protocol,
 					PID:       h.PID,
 					Process:   h.Process,
 					EventType: EventPortClosed,
 					Timestamp: now,
 				}
-				h.Events = append(h.Events, event)
-				t.addEvent(event)
+				t.recordEvent(h, event)
 			}
 		}
 	}
 
 	// Cleanup old histories if needed
-	t.cleanup()
+	t.cleanup(currentPortMap)
 }
 
 // GetUptime returns the uptime for a port
-func (t *Tracker) GetUptime(port int) time.Duration {
-	if h, exists := t.history[port]; exists && h.IsActive {
+func (t *Tracker) GetUptime(key PortKey) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if h, exists := t.history[key]; exists && h.IsActive {
 		return time.Since(h.FirstSeen)
 	}
 	return 0
 }
 
-// GetHistory returns the history for a specific port
-func (t *Tracker) GetHistory(port int) *PortHistory {
-	return t.history[port]
+// GetHistory returns a copy of the history for a specific port, or nil if
+// it isn't tracked
+func (t *Tracker) GetHistory(key PortKey) *PortHistory {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if h, exists := t.history[key]; exists {
+		return h.clone()
+	}
+	return nil
+}
+
+// clone copies a history, including its events
+func (h *PortHistory) clone() *PortHistory {
+	c := *h
+	c.Events = slices.Clone(h.Events)
+	c.samples = h.samples.clone()
+	return &c
 }
 
-// GetAllHistory returns all port histories
+// Forget drops everything recorded about a port number over any protocol,
+// including its events in the global event log, as if it had never been
+// seen
+func (t *Tracker) Forget(port int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.history {
+		if key.Port == port {
+			delete(t.history, key)
+		}
+	}
+	for key := range t.pending {
+		if key.Port == port {
+			delete(t.pending, key)
+		}
+	}
+
+	events := t.events[:0]
+	for _, e := range t.events {
+		if e.Port != port {
+			events = append(events, e)
+		}
+	}
+	t.events = events
+}
+
+// Reset drops every port history, staged port and event, as if the
+// tracker had just been created. Subscribers are kept.
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.history = make(map[PortKey]*PortHistory)
+	t.events = make([]PortEvent, 0)
+	t.pending = make(map[PortKey]*pendingPort)
+	t.transientCount = 0
+}
+
+// SortColumn selects the field port histories are ordered by
+type SortColumn int
+
+const (
+	SortByPort SortColumn = iota
+	SortByProcess
+	SortByFirstSeen
+	SortByLastSeen
+	SortByUptime
+	SortByOpenCount
+	sortColumnCount
+)
+
+// String returns the display name of the column
+func (c SortColumn) String() string {
+	switch c {
+	case SortByPort:
+		return "Port"
+	case SortByProcess:
+		return "Process"
+	case SortByFirstSeen:
+		return "First Seen"
+	case SortByLastSeen:
+		return "Last Seen"
+	case SortByUptime:
+		return "Uptime"
+	case SortByOpenCount:
+		return "Opens"
+	}
+	return "Unknown"
+}
+
+// Next returns the column after c, wrapping around
+func (c SortColumn) Next() SortColumn {
+	return (c + 1) % sortColumnCount
+}
+
+// GetAllHistory returns copies of all port histories, most recently seen
+// first
 func (t *Tracker) GetAllHistory() []*PortHistory {
+	return t.GetAllHistorySorted(SortByLastSeen, false)
+}
+
+// GetAllHistorySorted returns copies of all port histories ordered by
+// column
+func (t *Tracker) GetAllHistorySorted(column SortColumn, ascending bool) []*PortHistory {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.allHistorySorted(column, ascending)
+}
+
+// allHistorySorted is GetAllHistorySorted for callers holding the lock
+func (t *Tracker) allHistorySorted(column SortColumn, ascending bool) []*PortHistory {
 	histories := make([]*PortHistory, 0, len(t.history))
 	for _, h := range t.history {
-		histories = append(histories, h)
+		histories = append(histories, h.clone())
+	}
+
+	now := time.Now()
+	uptime := func(h *PortHistory) time.Duration {
+		if !h.IsActive {
+			return 0
+		}
+		return now.Sub(h.FirstSeen)
 	}
 
-	// Sort by last seen (most recent first)
 	sort.Slice(histories, func(i, j int) bool {
-		return histories[i].LastSeen.After(histories[j].LastSeen)
+		a, b := histories[i], histories[j]
+		var less bool
+		switch column {
+		case SortByPort:
+			less = a.Port < b.Port || a.Port == b.Port && a.Protocol < b.Protocol
+		case SortByProcess:
+			less = a.Process < b.Process
+		case SortByFirstSeen:
+			less = a.FirstSeen.Before(b.FirstSeen)
+		case SortByLastSeen:
+			less = a.LastSeen.Before(b.LastSeen)
+		case SortByUptime:
+			less = uptime(a) < uptime(b)
+		case SortByOpenCount:
+			less = a.OpenCount < b.OpenCount
+		}
+		if !ascending {
+			return !less
+		}
+		return less
 	})
 
 	return histories
 }
 
-// GetRecentEvents returns the most recent events
+// GetRecentEvents returns the most recent events, oldest first
 func (t *Tracker) GetRecentEvents(limit int) []PortEvent {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	if limit <= 0 || limit > len(t.events) {
 		limit = len(t.events)
 	}
@@ -173,11 +441,41 @@ func (t *Tracker) GetRecentEvents(limit int) []PortEvent {
 		start = 0
 	}
 
-	return t.events[start:]
+	return slices.Clone(t.events[start:])
+}
+
+// GetEventsForPort returns the recorded events for a port, oldest first
+func (t *Tracker) GetEventsForPort(key PortKey) []PortEvent {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if h, exists := t.history[key]; exists {
+		return slices.Clone(h.Events)
+	}
+	return nil
+}
+
+// IsFlapping reports whether a port has been repeatedly opening and closing
+func (t *Tracker) IsFlapping(key PortKey) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	h, exists := t.history[key]
+	if !exists {
+		return false
+	}
+
+	cutoff := time.Now().Add(-flapWindow)
+	transitions := 0
+	for i := len(h.Events) - 1; i >= 0 && h.Events[i].Timestamp.After(cutoff); i-- {
+		transitions++
+	}
+	return transitions >= flapThreshold
 }
 
 // GetStats returns tracking statistics
 func (t *Tracker) GetStats() HistoryStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	activeCount := 0
 	totalEvents := len(t.events)
 
@@ -191,17 +489,75 @@ func (t *Tracker) GetStats() HistoryStats {
 		TotalPortsTracked: len(t.history),
 		ActivePorts:       activeCount,
 		TotalEvents:       totalEvents,
+		TransientPorts:    t.transientCount,
 	}
 }
 
+// ProcessStats aggregates the port activity of a single process
+type ProcessStats struct {
+	Process     string
+	PortsOpened int // Distinct ports the process has been seen listening on
+	ActivePorts int // Ports it is listening on now
+	Restarts    int // Times its ports reopened after closing
+}
+
+// GetProcessStats groups the tracked port histories by process name
+func (t *Tracker) GetProcessStats() map[string]ProcessStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	stats := make(map[string]ProcessStats)
+	for _, h := range t.history {
+		s := stats[h.Process]
+		s.Process = h.Process
+		s.PortsOpened++
+		if h.IsActive {
+			s.ActivePorts++
+		}
+		s.Restarts += max(h.OpenCount-1, 0)
+		stats[h.Process] = s
+	}
+	return stats
+}
+
 // HistoryStats contains statistics about port tracking
 type HistoryStats struct {
 	TotalPortsTracked int
 	ActivePorts       int
 	TotalEvents       int
+	TransientPorts    int // Ports that closed before being considered stable
 }
 
-// addEvent adds an event to the tracker
+// recordEvent records a state transition on a port's history and in the
+// global event log. An event matching the port's last recorded transition
+// is dropped so repeated Updates can't double-record it. The caller holds
+// the write lock.
+func (t *Tracker) recordEvent(h *PortHistory, event PortEvent) {
+	if n := len(h.Events); n > 0 && h.Events[n-1].EventType == event.EventType {
+		return
+	}
+	h.Events = append(h.Events, event)
+
+	// Trim the port's own events so a flapping port can't grow unbounded
+	if len(h.Events) > t.maxPortEvents {
+		h.Events = append([]PortEvent(nil), h.Events[len(h.Events)-t.maxPortEvents:]...)
+	}
+
+	t.addEvent(event)
+	for _, fn := range t.subscribers {
+		fn(event)
+	}
+}
+
+// Subscribe registers fn to be called with each event as it's recorded,
+// during Update. It runs with the tracker locked, so it must not call the
+// tracker's methods.
+func (t *Tracker) Subscribe(fn func(PortEvent)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscribers = append(t.subscribers, fn)
+}
+
+// addEvent adds an event to the tracker. The caller holds the write lock.
 func (t *Tracker) addEvent(event PortEvent) {
 	t.events = append(t.events, event)
 
@@ -212,16 +568,20 @@ func (t *Tracker) addEvent(event PortEvent) {
 	}
 }
 
-// cleanup removes old inactive port histories
-func (t *Tracker) cleanup() {
+// cleanup removes the least recently seen inactive port histories once
+// the tracker exceeds maxHistories. Ports present in the current scan are
+// never evicted, and events belonging to evicted ports are dropped from
+// the global event log so they don't outlive their history. The caller
+// holds the write lock.
+func (t *Tracker) cleanup(current map[PortKey]scanner.PortInfo) {
 	if len(t.history) <= t.maxHistories {
 		return
 	}
 
-	// Get all inactive histories
+	// Get all inactive histories not seen in this scan
 	inactive := make([]*PortHistory, 0)
-	for _, h := range t.history {
-		if !h.IsActive {
+	for key, h := range t.history {
+		if _, seen := current[key]; !seen && !h.IsActive {
 			inactive = append(inactive, h)
 		}
 	}
@@ -232,10 +592,23 @@ func (t *Tracker) cleanup() {
 	})
 
 	// Remove oldest inactive histories
+	removed := make(map[PortKey]bool)
 	toRemove := len(t.history) - t.maxHistories
 	for i := 0; i < toRemove && i < len(inactive); i++ {
-		delete(t.history, inactive[i].Port)
+		delete(t.history, inactive[i].Key())
+		removed[inactive[i].Key()] = true
+	}
+
+	if len(removed) == 0 {
+		return
+	}
+	events := t.events[:0]
+	for _, e := range t.events {
+		if !removed[e.Key()] {
+			events = append(events, e)
+		}
 	}
+	t.events = events
 }
 
 // FormatUptime formats a duration as a human-readable string
@@ -0,0 +1,299 @@
+package history
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// eventTypes lists the types of a port's recorded events, oldest first
+func eventTypes(t *Tracker, port int) []EventType {
+	var types []EventType
+	for _, e := range t.GetEventsForPort(PortKey{Protocol: scanner.SocketTCP, Port: port}) {
+		types = append(types, e.EventType)
+	}
+	return types
+}
+
+func TestUpdateBackToBack(t *testing.T) {
+	tests := []struct {
+		name  string
+		scans [][]int
+		want  []EventType
+		log   int // Events expected in the global log
+	}{
+		{
+			name:  "repeated open",
+			scans: [][]int{{3000}, {3000}, {3000}},
+			want:  []EventType{EventPortOpened},
+			log:   1,
+		},
+		{
+			name:  "repeated close",
+			scans: [][]int{{3000}, {}, {}, {}},
+			want:  []EventType{EventPortOpened, EventPortClosed},
+			log:   2,
+		},
+		{
+			name:  "reopen",
+			scans: [][]int{{3000}, {}, {3000}, {3000}},
+			want:  []EventType{EventPortOpened, EventPortClosed, EventPortOpened},
+			log:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker(DefaultMaxEvents, DefaultMaxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+			for _, scan := range tt.scans {
+				tracker.Update(ports(scan...))
+			}
+			if got := eventTypes(tracker, 3000); !slices.Equal(got, tt.want) {
+				t.Errorf("events %v, want %v", got, tt.want)
+			}
+			if got := len(tracker.GetRecentEvents(0)); got != tt.log {
+				t.Errorf("event log has %d events, want %d", got, tt.log)
+			}
+		})
+	}
+}
+
+func TestLastDowntime(t *testing.T) {
+	const down = 20 * time.Millisecond
+	key := PortKey{Protocol: scanner.SocketTCP, Port: 3000}
+
+	tests := []struct {
+		name  string
+		scans [][]int
+		want  bool // Whether the port ends up with a recorded downtime
+	}{
+		{"never down", [][]int{{3000}, {3000}}, false},
+		{"still down", [][]int{{3000}, {}}, false},
+		{"down then up", [][]int{{3000}, {}, {3000}}, true},
+		{"down then up twice", [][]int{{3000}, {}, {3000}, {}, {3000}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker(DefaultMaxEvents, DefaultMaxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+			var lastSeen, reopened time.Time
+			for i, scan := range tt.scans {
+				if i > 0 {
+					time.Sleep(down)
+				}
+				if h := tracker.GetHistory(key); h != nil {
+					lastSeen = h.LastSeen
+				}
+				before := time.Now()
+				tracker.Update(ports(scan...))
+				if len(scan) > 0 {
+					reopened = before
+				}
+			}
+
+			h := tracker.GetHistory(key)
+			if h == nil {
+				t.Fatal("port not tracked")
+			}
+			if !tt.want {
+				if !h.LastDownStart.IsZero() || h.LastDownDuration != 0 {
+					t.Errorf("LastDownStart %v, LastDownDuration %v, want unset", h.LastDownStart, h.LastDownDuration)
+				}
+				return
+			}
+			if !h.LastDownStart.Equal(lastSeen) {
+				t.Errorf("LastDownStart = %v, want the time it was found closed, %v", h.LastDownStart, lastSeen)
+			}
+			if h.LastDownDuration < down || h.LastDownDuration > h.LastSeen.Sub(lastSeen) {
+				t.Errorf("LastDownDuration = %v, want between %v and %v", h.LastDownDuration, down, h.LastSeen.Sub(lastSeen))
+			}
+			if h.LastDownStart.Add(h.LastDownDuration).Before(reopened) {
+				t.Errorf("downtime ends at %v, before the reopening scan at %v", h.LastDownStart.Add(h.LastDownDuration), reopened)
+			}
+		})
+	}
+}
+
+func TestStagedPortEventOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		scans [][]int
+		want  []int // Ports of the logged events, oldest first
+	}{
+		{
+			name:  "close while another port is staged",
+			scans: [][]int{{3000}, {3000}, {3000}, {3000, 4000}, {4000}, {4000}},
+			want:  []int{3000, 3000, 4000},
+		},
+		{
+			name:  "open while another port is staged",
+			scans: [][]int{{4000}, {4000, 5000}, {4000, 5000}, {5000}, {5000}, {}},
+			want:  []int{4000, 5000, 4000, 5000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker(DefaultMaxEvents, DefaultMaxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+			tracker.SetStableThreshold(3)
+			var scanTimes []time.Time
+			for _, scan := range tt.scans {
+				time.Sleep(2 * time.Millisecond)
+				scanTimes = append(scanTimes, time.Now())
+				tracker.Update(ports(scan...))
+			}
+
+			events := tracker.GetRecentEvents(0)
+			var got []int
+			for i, e := range events {
+				got = append(got, e.Port)
+				if i > 0 && e.Timestamp.Before(events[i-1].Timestamp) {
+					t.Errorf("event %d (%s :%d at %v) is older than the one before it (%v)",
+						i, e.EventType, e.Port, e.Timestamp, events[i-1].Timestamp)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("logged ports %v, want %v", got, tt.want)
+			}
+
+			// The promoted port still remembers when it was first seen
+			last := tt.want[len(tt.want)-1]
+			h := tracker.GetHistory(PortKey{Protocol: scanner.SocketTCP, Port: last})
+			if h == nil {
+				t.Fatalf("port %d not tracked", last)
+			}
+			for i, scan := range tt.scans {
+				if slices.Contains(scan, last) {
+					if h.FirstSeen.Before(scanTimes[i]) || (i+1 < len(scanTimes) && !h.FirstSeen.Before(scanTimes[i+1])) {
+						t.Errorf("FirstSeen %v, want the scan at %v", h.FirstSeen, scanTimes[i])
+					}
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPortEventCap(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxEvents     int
+		maxPortEvents int
+		cycles        int
+	}{
+		{"default caps", DefaultMaxEvents, DefaultMaxPortEvents, 5000},
+		{"small port cap", DefaultMaxEvents, 10, 5000},
+		{"port cap above log cap", 50, 200, 5000},
+		{"single event", DefaultMaxEvents, 1, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker(tt.maxEvents, DefaultMaxHistories, tt.maxPortEvents, DefaultMaxSamples)
+			for range tt.cycles {
+				tracker.Update(ports(3000))
+				tracker.Update(ports())
+			}
+
+			events := eventTypes(tracker, 3000)
+			if len(events) != tt.maxPortEvents {
+				t.Errorf("port kept %d events, want %d", len(events), tt.maxPortEvents)
+			}
+			if events[len(events)-1] != EventPortClosed {
+				t.Errorf("newest event %s, want the final close", events[len(events)-1])
+			}
+			if got, want := len(tracker.GetRecentEvents(0)), min(2*tt.cycles, tt.maxEvents); got != want {
+				t.Errorf("event log has %d events, want %d", got, want)
+			}
+			if got := tracker.GetHistory(PortKey{Protocol: scanner.SocketTCP, Port: 3000}).OpenCount; got != tt.cycles {
+				t.Errorf("open count %d, want %d", got, tt.cycles)
+			}
+		})
+	}
+}
+
+func TestLoadTrimsPortEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	saved := NewTracker(DefaultMaxEvents, DefaultMaxHistories, 50, DefaultMaxSamples)
+	for range 40 {
+		saved.Update(ports(3000))
+		saved.Update(ports())
+	}
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := NewTracker(DefaultMaxEvents, DefaultMaxHistories, 10, DefaultMaxSamples)
+	if err := tracker.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(eventTypes(tracker, 3000)); got != 10 {
+		t.Errorf("restored port kept %d events, want 10", got)
+	}
+}
+
+func TestCleanupKeepsActivePorts(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxHistories int
+		closed       []int // Ports seen once, then closed
+		open         []int // Ports open in the last scan
+		wantTracked  []int
+	}{
+		{
+			name:         "one over capacity",
+			maxHistories: 3,
+			closed:       []int{4000},
+			open:         []int{3000, 3001, 3002},
+			wantTracked:  []int{3000, 3001, 3002},
+		},
+		{
+			name:         "all active over capacity",
+			maxHistories: 2,
+			open:         []int{3000, 3001, 3002},
+			wantTracked:  []int{3000, 3001, 3002},
+		},
+		{
+			name:         "reopened in the last scan",
+			maxHistories: 2,
+			closed:       []int{4000, 4001},
+			open:         []int{4000, 3000},
+			wantTracked:  []int{3000, 4000},
+		},
+		{
+			name:         "oldest closed evicted first",
+			maxHistories: 3,
+			closed:       []int{4000, 4001},
+			open:         []int{3000, 3001},
+			wantTracked:  []int{3000, 3001, 4001},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker(DefaultMaxEvents, tt.maxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+			for _, port := range tt.closed {
+				tracker.Update(ports(port))
+				tracker.Update(ports())
+			}
+			tracker.Update(ports(tt.open...))
+
+			var tracked []int
+			for _, h := range tracker.GetAllHistory() {
+				tracked = append(tracked, h.Port)
+			}
+			slices.Sort(tracked)
+			if !slices.Equal(tracked, tt.wantTracked) {
+				t.Errorf("tracked %v, want %v", tracked, tt.wantTracked)
+			}
+			for _, e := range tracker.GetRecentEvents(0) {
+				if !slices.Contains(tt.wantTracked, e.Port) {
+					t.Errorf("event log kept %s event for evicted port %d", e.EventType, e.Port)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/junjiang/gaze/internal/atomicfile"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Snapshot is the saved form of a tracker's histories and event log.
+// Times are written in RFC 3339 format.
+type Snapshot struct {
+	SavedAt   time.Time      `json:"saved_at"`
+	Histories []*PortHistory `json:"histories"`
+	Events    []PortEvent    `json:"events"`
+}
+
+// Snapshot returns the tracker's histories, most recently seen first, and
+// its event log
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return Snapshot{
+		SavedAt:   time.Now(),
+		Histories: t.allHistorySorted(SortByLastSeen, false),
+		Events:    append([]PortEvent(nil), t.events...),
+	}
+}
+
+// Save writes the tracker's snapshot to path. The file is replaced
+// atomically, so a crash mid-save leaves the previous history intact.
+func (t *Tracker) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	data, err := json.MarshalIndent(t.Snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by Save. A missing file yields an
+// empty snapshot.
+func ReadSnapshot(path string) (Snapshot, error) {
+	var snap Snapshot
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snap, nil
+	}
+	if err != nil {
+		return snap, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// Load restores the histories and events saved at path, within the
+// tracker's capacities. Ports that were open when the snapshot was saved
+// are recorded as closed then, since gaze stopped watching them; the next
+// Update reopens the ones still open, with the gap as their last downtime.
+func (t *Tracker) Load(path string) error {
+	snap, err := ReadSnapshot(path)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var closed []PortEvent
+	for _, h := range snap.Histories {
+		if h == nil || len(t.history) >= t.maxHistories {
+			continue
+		}
+		// Histories saved before UDP was tracked are all TCP
+		if h.Protocol == "" {
+			h.Protocol = scanner.SocketTCP
+			for i := range h.Events {
+				h.Events[i].Protocol = scanner.SocketTCP
+			}
+		}
+		if h.IsActive {
+			closed = append(closed, closeRestored(h, snap.SavedAt))
+		}
+		// The file may have been saved with a larger per-port cap
+		if len(h.Events) > t.maxPortEvents {
+			h.Events = h.Events[len(h.Events)-t.maxPortEvents:]
+		}
+		t.history[h.Key()] = h
+	}
+	for _, e := range snap.Events {
+		if e.Protocol == "" {
+			e.Protocol = scanner.SocketTCP
+		}
+		if _, kept := t.history[e.Key()]; kept {
+			t.addEvent(e)
+		}
+	}
+	for _, e := range closed {
+		t.addEvent(e)
+	}
+	return nil
+}
+
+// closeRestored marks a restored port closed at savedAt, or when it was
+// last seen for snapshots without a save time, and returns the closing
+// event. Subscribers aren't told, as nothing changed during this session.
+func closeRestored(h *PortHistory, savedAt time.Time) PortEvent {
+	if !savedAt.IsZero() {
+		h.LastSeen = savedAt
+	}
+	h.IsActive = false
+	event := PortEvent{
+		Port:      h.Port,
+		Protocol:  h.Protocol,
+		PID:       h.PID,
+		Process:   h.Process,
+		EventType: EventPortClosed,
+		Timestamp: h.LastSeen,
+	}
+	h.Events = append(h.Events, event)
+	return event
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestLoadClosesRestoredPorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		next       []int // Ports open in the first scan after loading
+		wantActive bool
+		wantOpens  int
+		wantEvents []EventType
+	}{
+		{
+			name:       "still open",
+			next:       []int{3000},
+			wantActive: true,
+			wantOpens:  2,
+			wantEvents: []EventType{EventPortOpened, EventPortClosed, EventPortOpened},
+		},
+		{
+			name:       "closed meanwhile",
+			next:       nil,
+			wantActive: false,
+			wantOpens:  1,
+			wantEvents: []EventType{EventPortOpened, EventPortClosed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.json")
+			saved := NewTracker(DefaultMaxEvents, DefaultMaxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+			saved.Update(ports(3000))
+			if err := saved.Save(path); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			snap, err := ReadSnapshot(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tracker := NewTracker(DefaultMaxEvents, DefaultMaxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+			if err := tracker.Load(path); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			key := PortKey{Protocol: scanner.SocketTCP, Port: 3000}
+			restored := tracker.GetHistory(key)
+			if restored.IsActive || !restored.LastSeen.Equal(snap.SavedAt) {
+				t.Fatalf("restored port active=%v last seen %v, want closed at %v", restored.IsActive, restored.LastSeen, snap.SavedAt)
+			}
+
+			tracker.Update(ports(tt.next...))
+			h := tracker.GetHistory(key)
+			if h.IsActive != tt.wantActive || h.OpenCount != tt.wantOpens {
+				t.Errorf("after scan active=%v opens=%d, want active=%v opens=%d", h.IsActive, h.OpenCount, tt.wantActive, tt.wantOpens)
+			}
+			if events := eventTypes(tracker, 3000); !slices.Equal(events, tt.wantEvents) {
+				t.Errorf("events %v, want %v", events, tt.wantEvents)
+			}
+			if tt.wantActive && !h.LastDownStart.Equal(snap.SavedAt) {
+				t.Errorf("last down start %v, want %v", h.LastDownStart, snap.SavedAt)
+			}
+			if n := len(tracker.GetRecentEvents(0)); n != len(tt.wantEvents) {
+				t.Errorf("event log has %d events, want %d", n, len(tt.wantEvents))
+			}
+		})
+	}
+}
+
+func TestSaveLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+	tracker := NewTracker(DefaultMaxEvents, DefaultMaxHistories, DefaultMaxPortEvents, DefaultMaxSamples)
+	tracker.Update(ports(3000))
+
+	for range 2 {
+		if err := tracker.Save(path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "history.json" {
+		t.Errorf("directory holds %v, want only history.json", entries)
+	}
+	if _, err := ReadSnapshot(path); err != nil {
+		t.Errorf("ReadSnapshot: %v", err)
+	}
+}
+
+// ports returns scanned TCP ports held by node
+func ports(numbers ...int) []scanner.PortInfo {
+	infos := make([]scanner.PortInfo, 0, len(numbers))
+	for _, port := range numbers {
+		infos = append(infos, scanner.PortInfo{Port: port, PID: int32(port), Process: "node", SocketType: scanner.SocketTCP})
+	}
+	return infos
+}
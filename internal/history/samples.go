@@ -0,0 +1,75 @@
+package history
+
+import (
+	"slices"
+	"time"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// DefaultMaxSamples is how many resource samples each port keeps by
+// default: three minutes of scans at the default interval
+const DefaultMaxSamples = 60
+
+// Sample is a port process's resource usage as seen by one scan
+type Sample struct {
+	Timestamp  time.Time `json:"timestamp"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemoryMB   float64   `json:"memory_mb"`
+}
+
+// sampleOf takes a sample of a scanned port's process
+func sampleOf(p scanner.PortInfo, at time.Time) Sample {
+	return Sample{Timestamp: at, CPUPercent: p.CPUPercent, MemoryMB: p.MemoryMB}
+}
+
+// sampleRing is a fixed-size ring buffer of samples. Once full, each new
+// sample overwrites the oldest, so a long-lived port's samples never
+// grow past the capacity.
+type sampleRing struct {
+	samples []Sample // Allocated with the ring's capacity on first add
+	start   int      // Index of the oldest sample once the ring is full
+}
+
+// add appends a sample, overwriting the oldest once size are held. A size
+// below 1 keeps nothing.
+func (r *sampleRing) add(s Sample, size int) {
+	switch {
+	case size < 1:
+		return
+	case r.samples == nil:
+		r.samples = make([]Sample, 0, size)
+	}
+	if len(r.samples) < cap(r.samples) {
+		r.samples = append(r.samples, s)
+		return
+	}
+	r.samples[r.start] = s
+	r.start = (r.start + 1) % len(r.samples)
+}
+
+// list returns a copy of the samples, oldest first
+func (r *sampleRing) list() []Sample {
+	return slices.Concat(r.samples[r.start:], r.samples[:r.start])
+}
+
+// clone copies the ring, so the copy doesn't share its buffer
+func (r sampleRing) clone() sampleRing {
+	if r.samples == nil {
+		return r
+	}
+	c := sampleRing{samples: make([]Sample, len(r.samples), cap(r.samples)), start: r.start}
+	copy(c.samples, r.samples)
+	return c
+}
+
+// GetSamples returns a port's resource samples, oldest first, or nil if
+// the port isn't tracked
+func (t *Tracker) GetSamples(key PortKey) []Sample {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if h, exists := t.history[key]; exists {
+		return h.samples.list()
+	}
+	return nil
+}
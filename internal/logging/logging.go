@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup routes gaze's internal diagnostics to a log file at the given
+// level. Without a path, logs are discarded so nothing writes over the
+// TUI. The returned closer should be closed on exit.
+func Setup(path, level string) (io.Closer, error) {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return io.NopCloser(nil), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	handler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: lvl})
+	slog.SetDefault(slog.New(handler))
+	return file, nil
+}
+
+// parseLevel converts a level name such as "debug" to a slog.Level
+func parseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q (use debug, info, warn or error)", level)
+}
@@ -0,0 +1,186 @@
+// Package render formats ports as plain text for the headless modes. It
+// has no terminal UI dependencies so its output stays clean when piped.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// ellipsis marks a truncated cell
+const ellipsis = "…"
+
+// ANSI sequences used when color is enabled
+const (
+	ansiBold  = "\x1b[1m"
+	ansiReset = "\x1b[0m"
+)
+
+// Column describes one column of the plain-text table
+type Column struct {
+	Name     string // Name accepted by ParseColumns
+	Title    string
+	MaxWidth int  // Cells longer than this are truncated; 0 means no limit
+	Right    bool // Right-align, for numbers
+	Value    func(scanner.PortInfo) string
+}
+
+// columns lists every available column in its default order
+var columns = []Column{
+	{Name: "port", Title: "PORT", Right: true, Value: func(p scanner.PortInfo) string { return p.Endpoint() }},
+	{Name: "proto", Title: "PROTO", Value: func(p scanner.PortInfo) string { return p.SocketType }},
+	{Name: "pid", Title: "PID", Right: true, Value: func(p scanner.PortInfo) string { return fmt.Sprintf("%d", p.PID) }},
+	{Name: "service", Title: "SERVICE", MaxWidth: 16, Value: func(p scanner.PortInfo) string { return dash(p.WellKnown) }},
+	{Name: "addr", Title: "ADDRESS", MaxWidth: 39, Value: func(p scanner.PortInfo) string { return dash(p.ListenAddr) }},
+	{Name: "process", Title: "PROCESS", MaxWidth: 24, Value: func(p scanner.PortInfo) string { return p.Process }},
+	{Name: "user", Title: "USER", MaxWidth: 16, Value: func(p scanner.PortInfo) string { return dash(p.User) }},
+	{Name: "command", Title: "COMMAND", MaxWidth: 60, Value: func(p scanner.PortInfo) string { return dash(p.Cmdline) }},
+	{Name: "container", Title: "CONTAINER", MaxWidth: 24, Value: func(p scanner.PortInfo) string { return dash(p.ContainerName) }},
+	{Name: "http", Title: "HTTP", Right: true, Value: func(p scanner.PortInfo) string { return orDash(p.HTTPStatus) }},
+	{Name: "latency", Title: "LATENCY", Right: true, Value: func(p scanner.PortInfo) string {
+		if p.Latency <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%dms", p.Latency.Milliseconds())
+	}},
+	{Name: "conns", Title: "CONNS", Right: true, Value: func(p scanner.PortInfo) string {
+		if p.SocketType != scanner.SocketTCP {
+			return "-"
+		}
+		return fmt.Sprintf("%d", p.Connections)
+	}},
+	{Name: "cpu", Title: "CPU%", Right: true, Value: func(p scanner.PortInfo) string { return fmt.Sprintf("%.1f", p.CPUPercent) }},
+	{Name: "mem", Title: "MEM(MB)", Right: true, Value: func(p scanner.PortInfo) string { return fmt.Sprintf("%.1f", p.MemoryMB) }},
+	{Name: "status", Title: "STATUS", Value: func(p scanner.PortInfo) string { return p.Status }},
+}
+
+// DefaultColumns is the column list used when none is selected
+const DefaultColumns = "port,proto,pid,process,container,http,status"
+
+// ColumnNames lists the names accepted by ParseColumns
+func ColumnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ParseColumns parses a comma-separated list of column names, such as
+// "port,process,mem", into columns in the given order
+func ParseColumns(s string) ([]Column, error) {
+	var selected []Column
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		c, ok := lookupColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (use %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+		selected = append(selected, c)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return selected, nil
+}
+
+// lookupColumn finds a column by name
+func lookupColumn(name string) (Column, bool) {
+	for _, c := range columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// Options controls table output
+type Options struct {
+	Color bool // Embolden the header with ANSI escapes
+}
+
+// Table writes ports as an aligned plain-text table with the given columns
+func Table(w io.Writer, ports []scanner.PortInfo, cols []Column, opts Options) error {
+	cells := make([][]string, len(ports))
+	widths := make([]int, len(cols))
+	for i, c := range cols {
+		widths[i] = utf8.RuneCountInString(c.Title)
+	}
+	for r, p := range ports {
+		cells[r] = make([]string, len(cols))
+		for i, c := range cols {
+			cell := truncate(c.Value(p), c.MaxWidth)
+			cells[r][i] = cell
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.Title
+	}
+	header := formatRow(titles, cols, widths)
+	if opts.Color {
+		header = ansiBold + header + ansiReset
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	for _, row := range cells {
+		if _, err := fmt.Fprintln(w, formatRow(row, cols, widths)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatRow pads each cell to its column width, separated by two spaces.
+// Trailing padding is dropped.
+func formatRow(row []string, cols []Column, widths []int) string {
+	var b strings.Builder
+	for i, cell := range row {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if cols[i].Right {
+			b.WriteString(pad + cell)
+		} else {
+			b.WriteString(cell + pad)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// truncate shortens s to at most width runes, ending it with an ellipsis
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + ellipsis
+}
+
+// dash returns s, or "-" if it is empty
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// orDash formats n, or "-" if it is zero
+func orDash(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", n)
+}
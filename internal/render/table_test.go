@@ -0,0 +1,131 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestTable(t *testing.T) {
+	ports := []scanner.PortInfo{
+		{Port: 80, PID: 1, Process: "nginx", SocketType: scanner.SocketTCP, Status: "LISTEN"},
+		{Port: 3000, PID: 4242, Process: "node", SocketType: scanner.SocketTCP, Status: "LISTEN"},
+	}
+
+	tests := []struct {
+		name    string
+		columns string
+		ports   []scanner.PortInfo
+		opts    Options
+		want    string
+	}{
+		{
+			name:    "numbers right, text left",
+			columns: "port,pid,process",
+			ports:   ports,
+			want: "PORT   PID  PROCESS\n" +
+				"  80     1  nginx\n" +
+				"3000  4242  node\n",
+		},
+		{
+			name:    "header only",
+			columns: "port,process",
+			want:    "PORT  PROCESS\n",
+		},
+		{
+			name:    "truncated process",
+			columns: "process,pid",
+			ports:   []scanner.PortInfo{{PID: 7, Process: strings.Repeat("x", 30)}},
+			want: "PROCESS                   PID\n" +
+				strings.Repeat("x", 23) + "…    7\n",
+		},
+		{
+			name:    "wide characters counted as runes",
+			columns: "process,pid",
+			ports:   []scanner.PortInfo{{PID: 7, Process: "café"}, {PID: 8, Process: "db"}},
+			want: "PROCESS  PID\n" +
+				"café       7\n" +
+				"db         8\n",
+		},
+		{
+			name:    "empty cells dashed",
+			columns: "port,container,user",
+			ports:   []scanner.PortInfo{{Port: 22}},
+			want: "PORT  CONTAINER  USER\n" +
+				"  22  -          -\n",
+		},
+		{
+			name:    "color header",
+			columns: "port",
+			ports:   ports[:1],
+			opts:    Options{Color: true},
+			want:    ansiBold + "PORT" + ansiReset + "\n  80\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, err := ParseColumns(tt.columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := Table(&b, tt.ports, cols, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Table() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"nginx", 0, "nginx"},
+		{"nginx", 5, "nginx"},
+		{"nginx", 4, "ngi…"},
+		{"cafébar", 5, "café…"},
+		{"ab", 1, "…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{DefaultColumns, strings.Split(DefaultColumns, ","), false},
+		{" Port , MEM ", []string{"port", "mem"}, false},
+		{"port,,pid", []string{"port", "pid"}, false},
+		{"port,bogus", nil, true},
+		{"", nil, true},
+	}
+
+	for _, tt := range tests {
+		cols, err := ParseColumns(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseColumns(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		var names []string
+		for _, c := range cols {
+			names = append(names, c.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ParseColumns(%q) = %v, want %v", tt.spec, names, tt.want)
+		}
+	}
+}
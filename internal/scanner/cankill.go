@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// CanKill reports whether gaze may signal pid: it runs as root, or it
+// shares a user with the process as kill(2) requires. When the owner
+// can't be read it assumes so and leaves the kill to report any failure.
+func CanKill(pid int32) bool {
+	if pid == 0 {
+		return false
+	}
+	return canSignal(&process.Process{Pid: pid})
+}
+
+// canSignal applies the kill(2) permission rule: the sender's real or
+// effective UID must match the target's real or saved UID. Windows isn't
+// checked.
+func canSignal(p *process.Process) bool {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return true
+	}
+	uids, err := p.Uids()
+	if err != nil || len(uids) == 0 {
+		return true
+	}
+
+	targets := []int32{uids[0]}
+	if len(uids) > 2 {
+		targets = append(targets, uids[2])
+	}
+	for _, sender := range []int{os.Getuid(), os.Geteuid()} {
+		for _, target := range targets {
+			if int32(sender) == target {
+				return true
+			}
+		}
+	}
+	return false
+}
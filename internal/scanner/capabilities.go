@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Capability is an optional part of gaze that depends on the environment
+type Capability struct {
+	Name    string
+	Enabled bool
+	Detail  string // What it provides, or why it's unavailable
+}
+
+// ProbeCapabilities checks which optional features work in this
+// environment, so missing details can be explained rather than left blank
+func ProbeCapabilities() []Capability {
+	return []Capability{
+		probeDocker(),
+		probeProc(),
+		{Name: "renice", Enabled: CanRenice(), Detail: "changing process priorities"},
+	}
+}
+
+// probeDocker reports whether container details can be looked up
+func probeDocker() Capability {
+	c := Capability{Name: "docker", Enabled: DockerAvailable(), Detail: "container names and images"}
+	if !c.Enabled {
+		c.Detail = "docker CLI not found, so container names and images aren't shown"
+	}
+	return c
+}
+
+// probeProc reports whether the Linux /proc details are readable
+func probeProc() Capability {
+	c := Capability{Name: "/proc", Detail: "accept queues, network namespaces and unix sockets"}
+	switch {
+	case runtime.GOOS != "linux":
+		c.Detail = "not Linux, so accept queues, network namespaces and unix sockets aren't shown"
+	default:
+		f, err := os.Open("/proc/net/tcp")
+		if err != nil {
+			c.Detail = "/proc/net isn't readable, so accept queues, network namespaces and unix sockets aren't shown"
+			break
+		}
+		f.Close()
+		c.Enabled = true
+	}
+	return c
+}
+
+// PrivilegeCapability reports the missing privileges behind a scan that
+// couldn't read the processes of denied sockets. It isn't probed up front,
+// since an unprivileged gaze only misses details when other users' ports
+// are open.
+func PrivilegeCapability(denied int) Capability {
+	return Capability{
+		Name:   "privileges",
+		Detail: fmt.Sprintf("%d socket(s) belong to processes gaze may not read, so they show as Unknown and can't be killed; run as root to see them", denied),
+	}
+}
+
+// Degraded returns the capabilities that aren't available
+func Degraded(caps []Capability) []Capability {
+	var degraded []Capability
+	for _, c := range caps {
+		if !c.Enabled {
+			degraded = append(degraded, c)
+		}
+	}
+	return degraded
+}
@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// ParseBindCIDR parses a CIDR such as "10.0.0.0/8" for Config.BindCIDR
+func ParseBindCIDR(s string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: %w", s, err)
+	}
+	return prefix.Masked(), nil
+}
+
+// boundWithin reports whether a listen address is reachable on addresses
+// in prefix. Wildcard binds (0.0.0.0, ::) listen on every address and so
+// match any prefix. An invalid prefix matches everything.
+func boundWithin(listenAddr string, prefix netip.Prefix) bool {
+	if !prefix.IsValid() {
+		return true
+	}
+	addr, err := netip.ParseAddr(listenAddr)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	if addr.IsUnspecified() {
+		return true
+	}
+	return prefix.Contains(addr)
+}
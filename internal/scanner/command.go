@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// commandTimeout bounds external commands such as renice and nginx -T, so
+// a stuck command can't freeze a scan or the UI
+const commandTimeout = 5 * time.Second
+
+// ErrCommandTimeout is returned when an external command is killed for
+// running longer than its timeout
+var ErrCommandTimeout = errors.New("timed out")
+
+// execCommand creates the external commands gaze runs. Tests replace it to
+// run a stand-in, such as a sleep that outlasts the timeout.
+var execCommand = exec.CommandContext
+
+// runCommand runs name with args for at most timeout, or until parent is
+// done if that comes first, and returns its standard output. A command
+// that runs out of time is killed and fails with ErrCommandTimeout, naming
+// it by label.
+func runCommand(parent context.Context, timeout time.Duration, label, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	out, err := execCommand(ctx, name, args...).Output()
+	if err := timeoutError(ctx, label, timeout); err != nil {
+		return nil, err
+	}
+	return out, err
+}
+
+// commandStderr returns what a failed command wrote to stderr, or the
+// error itself if it wrote nothing
+func commandStderr(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+		return string(bytes.TrimSpace(exitErr.Stderr))
+	}
+	return err.Error()
+}
+
+// timeoutError returns an ErrCommandTimeout naming the command if ctx
+// expired while it ran, and nil otherwise
+func timeoutError(ctx context.Context, command string, timeout time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s %w after %s", command, ErrCommandTimeout, timeout)
+	}
+	return nil
+}
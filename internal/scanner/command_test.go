@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCommand makes runCommand run stand-in instead of the commands it is
+// given, recording what it was asked to run
+func fakeCommand(t *testing.T, stand []string) *[]string {
+	path, err := exec.LookPath(stand[0])
+	if err != nil {
+		t.Skipf("%s not available", stand[0])
+	}
+	var ran []string
+	saved := execCommand
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		ran = append([]string{name}, args...)
+		return exec.CommandContext(ctx, path, stand[1:]...)
+	}
+	t.Cleanup(func() { execCommand = saved })
+	return &ran
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		stand       []string // Command run in place of renice
+		timeout     time.Duration
+		want        string // Output
+		wantTimeout bool
+		wantStderr  string // What the failure reports, if it fails otherwise
+	}{
+		{"finishes in time", []string{"echo", "done"}, 5 * time.Second, "done\n", false, ""},
+		{"hangs past the timeout", []string{"sleep", "10"}, 50 * time.Millisecond, "", true, ""},
+		{"fails", []string{"sh", "-c", "echo denied >&2; exit 1"}, 5 * time.Second, "", false, "denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := fakeCommand(t, tt.stand)
+
+			start := time.Now()
+			out, err := runCommand(context.Background(), tt.timeout, "renice", "renice", "5", "-p", "42")
+
+			if want := []string{"renice", "5", "-p", "42"}; !slices.Equal(*ran, want) {
+				t.Errorf("ran %v, want %v", *ran, want)
+			}
+			if got := errors.Is(err, ErrCommandTimeout); got != tt.wantTimeout {
+				t.Fatalf("error %v, want timeout %v", err, tt.wantTimeout)
+			}
+			if tt.wantTimeout {
+				if elapsed := time.Since(start); elapsed > 5*time.Second {
+					t.Errorf("command ran %s, want it killed at the timeout", elapsed)
+				}
+				if !strings.HasPrefix(err.Error(), "renice timed out after") {
+					t.Errorf("error %q doesn't name the command and timeout", err)
+				}
+				return
+			}
+			if tt.wantStderr != "" {
+				if err == nil || commandStderr(err) != tt.wantStderr {
+					t.Errorf("error %v, want one reporting %q", err, tt.wantStderr)
+				}
+				return
+			}
+			if err != nil || string(out) != tt.want {
+				t.Errorf("runCommand = %q, %v; want %q", out, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommandParentDeadline(t *testing.T) {
+	// A docker call's deadline also covers waiting for a slot, so the
+	// parent's deadline can come before the command's own timeout
+	fakeCommand(t, []string{"sleep", "10"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := runCommand(ctx, time.Minute, "docker ps", "docker", "ps")
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Errorf("error %v, want ErrCommandTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command ran %s, want it killed at the parent's deadline", elapsed)
+	}
+}
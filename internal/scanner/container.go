@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// containerCacheTTL is how long the last good container map is reused
+// while the Docker daemon is unreachable
+const containerCacheTTL = 30 * time.Second
+
+// Limits on docker CLI calls, so overlapping scans can't pile up docker
+// processes and a hung daemon can't stall a scan
+const (
+	maxDockerCalls    = 2
+	dockerCallTimeout = 5 * time.Second
+)
+
+// dockerCalls holds a slot for each docker process currently running
+var dockerCalls = make(chan struct{}, maxDockerCalls)
+
+// ContainerInfo describes the container publishing a port
+type ContainerInfo struct {
+	ID    string
+	Name  string
+	Image string
+}
+
+// containerCache remembers the last successful container lookup so brief
+// daemon hiccups don't make container labels disappear
+var containerCache struct {
+	sync.Mutex
+	ports   map[int]ContainerInfo
+	updated time.Time
+	lastErr error
+}
+
+// DockerError returns the error from the most recent Docker lookup, or nil
+// if it succeeded or Docker isn't installed
+func DockerError() error {
+	containerCache.Lock()
+	defer containerCache.Unlock()
+	return containerCache.lastErr
+}
+
+// DockerAvailable reports whether the docker CLI is installed
+func DockerAvailable() bool {
+	_, err := exec.LookPath("docker")
+	return err == nil
+}
+
+// getContainerInfo maps published host ports to the containers exposing
+// them. When Docker fails, the last known map is returned for a short
+// while so transient errors don't blank out container details.
+func getContainerInfo() map[int]ContainerInfo {
+	if !DockerAvailable() {
+		return nil
+	}
+
+	ports, err := listContainerPorts()
+
+	containerCache.Lock()
+	defer containerCache.Unlock()
+
+	if err != nil {
+		slog.Warn("docker lookup failed, using cached container info", "error", err,
+			"cache_age", time.Since(containerCache.updated).Round(time.Second))
+		containerCache.lastErr = err
+		if time.Since(containerCache.updated) < containerCacheTTL {
+			return containerCache.ports
+		}
+		return nil
+	}
+
+	containerCache.ports = ports
+	containerCache.updated = time.Now()
+	containerCache.lastErr = nil
+	return ports
+}
+
+// listContainerPorts runs `docker ps` and parses the published ports
+func listContainerPorts() (map[int]ContainerInfo, error) {
+	out, err := runDocker("ps", "--format", "{{.ID}}\t{{.Names}}\t{{.Image}}\t{{.Ports}}")
+	if err != nil {
+		return nil, fmt.Errorf("docker ps failed: %w", err)
+	}
+
+	ports := make(map[int]ContainerInfo)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 {
+			continue
+		}
+		info := ContainerInfo{ID: fields[0], Name: fields[1], Image: fields[2]}
+		for _, port := range parsePublishedPorts(fields[3]) {
+			ports[port] = info
+		}
+	}
+	return ports, nil
+}
+
+// runDocker runs the docker CLI with args and returns its output. At most
+// maxDockerCalls run at once, and waiting for a slot counts towards the
+// call's timeout.
+func runDocker(args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerCallTimeout)
+	defer cancel()
+
+	release, err := acquireDockerCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return runCommand(ctx, dockerCallTimeout, "docker "+args[0], "docker", args...)
+}
+
+// acquireDockerCall waits for a free docker call slot until ctx is done.
+// On success the returned func gives the slot back.
+func acquireDockerCall(ctx context.Context) (func(), error) {
+	select {
+	case dockerCalls <- struct{}{}:
+		return func() { <-dockerCalls }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("too many docker calls in progress: %w", ctx.Err())
+	}
+}
+
+// parsePublishedPorts extracts host ports from a `docker ps` ports column,
+// e.g. "0.0.0.0:8080->80/tcp, :::8080->80/tcp, 0.0.0.0:9000-9001->9000-9001/tcp"
+func parsePublishedPorts(s string) []int {
+	var ports []int
+	for _, mapping := range strings.Split(s, ",") {
+		host, _, ok := strings.Cut(strings.TrimSpace(mapping), "->")
+		if !ok {
+			// Exposed but not published
+			continue
+		}
+		host = host[strings.LastIndex(host, ":")+1:]
+
+		startStr, endStr, isRange := strings.Cut(host, "-")
+		start, err := strconv.Atoi(startStr)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(endStr); err != nil {
+				continue
+			}
+		}
+		for p := start; p <= end; p++ {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireDockerCallBoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		callers int
+	}{
+		{"fewer than the limit", maxDockerCalls - 1},
+		{"at the limit", maxDockerCalls},
+		{"well over the limit", 4 * maxDockerCalls},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int32
+			var wg sync.WaitGroup
+			for range tt.callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					release, err := acquireDockerCall(context.Background())
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					defer release()
+
+					n := running.Add(1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					running.Add(-1)
+				}()
+			}
+			wg.Wait()
+
+			if got := int(peak.Load()); got > maxDockerCalls {
+				t.Errorf("peak concurrent calls = %d, want at most %d", got, maxDockerCalls)
+			}
+			if len(dockerCalls) != 0 {
+				t.Errorf("%d slots still held after all calls finished", len(dockerCalls))
+			}
+		})
+	}
+}
+
+func TestAcquireDockerCallTimesOut(t *testing.T) {
+	var releases []func()
+	for range maxDockerCalls {
+		release, err := acquireDockerCall(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+	defer func() {
+		for _, release := range releases {
+			release()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := acquireDockerCall(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquireDockerCall with all slots held = %v, want DeadlineExceeded", err)
+	}
+}
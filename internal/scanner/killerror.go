@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// KillFailure classifies why a process couldn't be killed
+type KillFailure int
+
+const (
+	KillFailedOther      KillFailure = iota
+	KillFailedPermission             // Not allowed to signal the process
+	KillFailedGone                   // The process had already exited
+)
+
+// KillError is returned when killing a process fails
+type KillError struct {
+	PID    int32
+	Reason KillFailure
+	Err    error
+}
+
+// Error implements the error interface
+func (e *KillError) Error() string {
+	switch e.Reason {
+	case KillFailedPermission:
+		return fmt.Sprintf("permission denied killing PID %d", e.PID)
+	case KillFailedGone:
+		return fmt.Sprintf("PID %d has already exited", e.PID)
+	}
+	return fmt.Sprintf("failed to kill PID %d: %v", e.PID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *KillError) Unwrap() error {
+	return e.Err
+}
+
+// Hint returns guidance on what to do about the failure
+func (e *KillError) Hint() string {
+	switch e.Reason {
+	case KillFailedPermission:
+		return "the process belongs to another user; try running gaze with sudo"
+	case KillFailedGone:
+		return "rescan to refresh the list"
+	}
+	return ""
+}
+
+// IsProcessGone reports whether err means the process had already exited
+func IsProcessGone(err error) bool {
+	var killErr *KillError
+	return errors.As(err, &killErr) && killErr.Reason == KillFailedGone
+}
+
+// classifyKillError wraps a signal delivery error in a KillError
+func classifyKillError(pid int32, err error) *KillError {
+	reason := KillFailedOther
+	switch {
+	case errors.Is(err, os.ErrProcessDone), errors.Is(err, syscall.ESRCH):
+		reason = KillFailedGone
+	case errors.Is(err, os.ErrPermission):
+		reason = KillFailedPermission
+	}
+	return &KillError{PID: pid, Reason: reason, Err: err}
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyKillError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		want     KillFailure
+		gone     bool
+		wantHint bool
+	}{
+		{"process done", os.ErrProcessDone, KillFailedGone, true, true},
+		{"no such process", syscall.ESRCH, KillFailedGone, true, true},
+		{"wrapped no such process", fmt.Errorf("signal: %w", syscall.ESRCH), KillFailedGone, true, true},
+		{"not permitted", syscall.EPERM, KillFailedPermission, false, true},
+		{"access denied", os.ErrPermission, KillFailedPermission, false, true},
+		{"other", errors.New("device busy"), KillFailedOther, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyKillError(1234, tt.err)
+			if err.Reason != tt.want {
+				t.Errorf("Reason = %v, want %v", err.Reason, tt.want)
+			}
+			if got := IsProcessGone(err); got != tt.gone {
+				t.Errorf("IsProcessGone = %v, want %v", got, tt.gone)
+			}
+			if got := err.Hint() != ""; got != tt.wantHint {
+				t.Errorf("Hint() = %q, want a hint: %v", err.Hint(), tt.wantHint)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v doesn't unwrap to %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestKillProcessGone(t *testing.T) {
+	// Above any system's PID limit, so no process can hold it
+	const pid = math.MaxInt32
+
+	for _, sig := range []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL} {
+		if err := KillProcessWithSignal(pid, sig); !IsProcessGone(err) {
+			t.Errorf("KillProcessWithSignal(%d, %v) = %v, want a gone process", pid, sig, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// hostNetNS caches the network namespace gaze itself runs in
+var hostNetNS = sync.OnceValue(func() string {
+	return readNetNS("self")
+})
+
+// getNetNS returns the network namespace identifier of a process, such as
+// "net:[4026531840]". It returns "" on non-Linux systems or when
+// /proc/PID/ns/net can't be read, typically for lack of permission.
+func getNetNS(pid int32) string {
+	if pid == 0 {
+		return ""
+	}
+	return readNetNS(fmt.Sprintf("%d", pid))
+}
+
+// readNetNS reads the namespace link for a /proc entry
+func readNetNS(proc string) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	ns, err := os.Readlink(fmt.Sprintf("/proc/%s/ns/net", proc))
+	if err != nil {
+		return ""
+	}
+	return ns
+}
+
+// InHostNetNS reports whether a port is bound in the same network
+// namespace as gaze. Unknown namespaces are assumed to be the host's.
+func (p PortInfo) InHostNetNS() bool {
+	return p.NetNS == "" || hostNetNS() == "" || p.NetNS == hostNetNS()
+}
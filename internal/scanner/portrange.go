@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortRange is an inclusive range of port numbers
+type PortRange struct {
+	Start int
+	End   int
+}
+
+// ParsePortRange parses "3000-3010" or a single port such as "8080"
+func ParsePortRange(s string) (PortRange, error) {
+	startStr, endStr, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	if !isRange {
+		endStr = startStr
+	}
+
+	start, err := parsePort(startStr)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+	end, err := parsePort(endStr)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+	if start > end {
+		return PortRange{}, fmt.Errorf("invalid port range %q: start is after end", s)
+	}
+
+	return PortRange{Start: start, End: end}, nil
+}
+
+// ParsePortList parses a comma-separated list of ports and ranges, such as
+// "631,5353,6000-6010". An empty string yields no ranges.
+func ParsePortList(s string) ([]PortRange, error) {
+	var ranges []PortRange
+	for _, field := range strings.Split(s, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		r, err := ParsePortRange(field)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// Contains reports whether port falls within the range
+func (r PortRange) Contains(port int) bool {
+	return port >= r.Start && port <= r.End
+}
+
+// String formats the range as it would be parsed
+func (r PortRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// MarshalJSON writes a single port as a number and a range as a string
+// such as "6000-6010"
+func (r PortRange) MarshalJSON() ([]byte, error) {
+	if r.Start == r.End {
+		return json.Marshal(r.Start)
+	}
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON reads a port number or a range string, as MarshalJSON
+// writes them
+func (r *PortRange) UnmarshalJSON(data []byte) error {
+	var port int
+	if err := json.Unmarshal(data, &port); err == nil {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("port %d out of range 1-65535", port)
+		}
+		*r = PortRange{Start: port, End: port}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid port range %s: not a number or string", data)
+	}
+	parsed, err := ParsePortRange(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// WithoutPorts returns ports minus those whose numbers fall within any of
+// ranges, e.g. ignored ports. Unix sockets have no port number and are
+// always kept.
+func WithoutPorts(ports []PortInfo, ranges []PortRange) []PortInfo {
+	if len(ranges) == 0 {
+		return ports
+	}
+	kept := make([]PortInfo, 0, len(ports))
+	for _, p := range ports {
+		if p.SocketType == SocketUnix || !inRanges(ranges, p.Port) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// parsePort parses a single port number
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %d out of range 1-65535", port)
+	}
+	return port, nil
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestPortRangeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []PortRange
+		wantErr bool
+	}{
+		{"ports", `[631, 5353]`, []PortRange{{631, 631}, {5353, 5353}}, false},
+		{"ranges", `["6000-6010", 8080]`, []PortRange{{6000, 6010}, {8080, 8080}}, false},
+		{"single port string", `["443"]`, []PortRange{{443, 443}}, false},
+		{"out of range", `[70000]`, nil, true},
+		{"backwards", `["10-1"]`, nil, true},
+		{"not a port", `[true]`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []PortRange
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.data, got, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var again []PortRange
+			if err := json.Unmarshal(data, &again); err != nil || !slices.Equal(again, got) {
+				t.Errorf("round trip through %s = %v, %v", data, again, err)
+			}
+		})
+	}
+}
+
+func TestWithoutPorts(t *testing.T) {
+	ports := []PortInfo{
+		{Port: 631, SocketType: SocketTCP},
+		{Port: 5353, SocketType: SocketUDP},
+		{Port: 8080, SocketType: SocketTCP},
+		{SocketType: SocketUnix, SocketPath: "/run/docker.sock"},
+	}
+
+	tests := []struct {
+		name   string
+		ranges []PortRange
+		want   []int
+	}{
+		{"none ignored", nil, []int{631, 5353, 8080, 0}},
+		{"single port", []PortRange{{631, 631}}, []int{5353, 8080, 0}},
+		{"range", []PortRange{{5000, 9000}}, []int{631, 0}},
+		{"every port", []PortRange{{1, 65535}}, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, p := range WithoutPorts(ports, tt.ranges) {
+				got = append(got, p.Port)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WithoutPorts(%v) kept %v, want %v", tt.ranges, got, tt.want)
+			}
+		})
+	}
+}
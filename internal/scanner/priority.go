@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Niceness bounds on Unix systems
+const (
+	MinNiceness = -20
+	MaxNiceness = 19
+)
+
+// linuxPriorityBase is what Linux's getpriority system call adds to a nice
+// value: it reports 20-nice, from 40 for nice -20 down to 1 for nice 19
+const linuxPriorityBase = 20
+
+// niceFromPriority converts the priority gopsutil reads on Linux, the raw
+// getpriority value, to a nice value
+func niceFromPriority(priority int32) int32 {
+	return linuxPriorityBase - priority
+}
+
+// processNiceness reads the nice value of a process
+func processNiceness(p *process.Process) (int32, error) {
+	n, err := p.Nice()
+	if err != nil {
+		return 0, err
+	}
+	if runtime.GOOS == "linux" {
+		n = niceFromPriority(n)
+	}
+	return n, nil
+}
+
+// StepNiceness returns niceness moved by delta, kept within the bounds
+// renice accepts. A negative delta raises the priority.
+func StepNiceness(niceness, delta int32) int32 {
+	return min(max(niceness+delta, MinNiceness), MaxNiceness)
+}
+
+// CanRenice reports whether process priorities can be changed on this platform
+func CanRenice() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := exec.LookPath("renice")
+	return err == nil
+}
+
+// ReniceProcess sets the niceness of a process using renice
+func ReniceProcess(pid int32, niceness int32) error {
+	if pid == 0 {
+		return fmt.Errorf("invalid PID: 0")
+	}
+	if !CanRenice() {
+		return fmt.Errorf("renice is not supported on %s", runtime.GOOS)
+	}
+	if niceness < MinNiceness || niceness > MaxNiceness {
+		return fmt.Errorf("niceness %d out of range [%d, %d]", niceness, MinNiceness, MaxNiceness)
+	}
+
+	// The absolute "renice PRIORITY -p PID" form is understood by both
+	// util-linux and BSD renice
+	_, err := runCommand(context.Background(), commandTimeout, "renice", "renice", strconv.Itoa(int(niceness)), "-p", strconv.Itoa(int(pid)))
+	if errors.Is(err, ErrCommandTimeout) {
+		slog.Warn("renice timed out", "pid", pid, "niceness", niceness)
+		return err
+	}
+	if err != nil {
+		slog.Warn("renice failed", "pid", pid, "niceness", niceness, "error", err, "output", commandStderr(err))
+		return fmt.Errorf("renice failed: %s", commandStderr(err))
+	}
+	slog.Info("reniced process", "pid", pid, "niceness", niceness)
+	return nil
+}
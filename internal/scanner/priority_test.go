@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func TestNiceFromPriority(t *testing.T) {
+	tests := []struct {
+		priority int32
+		want     int32
+	}{
+		{20, 0},
+		{15, 5},
+		{40, -20},
+		{1, 19},
+	}
+
+	for _, tt := range tests {
+		if got := niceFromPriority(tt.priority); got != tt.want {
+			t.Errorf("niceFromPriority(%d) = %d, want %d", tt.priority, got, tt.want)
+		}
+	}
+}
+
+func TestStepNiceness(t *testing.T) {
+	tests := []struct {
+		name     string
+		niceness int32
+		delta    int32
+		want     int32
+	}{
+		{"lower priority", 0, 5, 5},
+		{"raise priority", 0, -5, -5},
+		{"clamped at the lowest priority", 17, 5, MaxNiceness},
+		{"clamped at the highest priority", -18, -5, MinNiceness},
+		{"already lowest", MaxNiceness, 5, MaxNiceness},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StepNiceness(tt.niceness, tt.delta); got != tt.want {
+				t.Errorf("StepNiceness(%d, %d) = %d, want %d", tt.niceness, tt.delta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessNiceness(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("nice values are Unix only")
+	}
+	nice, err := exec.LookPath("nice")
+	if err != nil {
+		t.Skip("nice not available")
+	}
+
+	tests := []struct {
+		adjustment string
+		want       int32
+	}{
+		{"0", 0},
+		{"5", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run("nice "+tt.adjustment, func(t *testing.T) {
+			cmd := exec.Command(nice, "-n", tt.adjustment, "sleep", "60")
+			if err := cmd.Start(); err != nil {
+				t.Skip("can't start sleep:", err)
+			}
+			defer func() {
+				cmd.Process.Kill()
+				cmd.Wait()
+			}()
+
+			p, err := process.NewProcess(int32(cmd.Process.Pid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// nice sets the priority before running sleep, so wait for sleep
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				if name, _ := p.Name(); name == "sleep" {
+					break
+				}
+			}
+			// Measured against this process, in case it runs niced itself
+			self, err := process.NewProcess(int32(os.Getpid()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			base, err := processNiceness(self)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := processNiceness(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := min(base+tt.want, MaxNiceness); got != want {
+				t.Errorf("niceness = %d, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"sync"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// cachedProc holds the details of a process that don't change while it
+// runs, so they are read once rather than on every scan
+type cachedProc struct {
+	proc       *process.Process
+	createTime int64 // Start time, to tell a reused PID from the original
+	name       string
+	shortName  string
+	user       string // Owning user, or UnknownUser
+	cmdline    string // Full command line, or "" if unreadable
+	canKill    bool   // Whether gaze may signal the process
+	lastScan   uint64 // Last scan the process was seen in
+}
+
+// procCache maps PIDs to their cached details. Entries are dropped once a
+// scan no longer sees the PID, and replaced when the PID is reused.
+var procCache = struct {
+	sync.Mutex
+	entries map[int32]*cachedProc
+	scan    uint64
+	hits    int
+	misses  int
+	denied  int // Sockets whose process couldn't be read for lack of permission
+}{entries: make(map[int32]*cachedProc)}
+
+// beginProcScan starts a scan generation for the process cache
+func beginProcScan() {
+	procCache.Lock()
+	defer procCache.Unlock()
+	procCache.scan++
+	procCache.hits, procCache.misses, procCache.denied = 0, 0, 0
+}
+
+// endProcScan evicts processes that weren't seen in the scan that just
+// finished
+func endProcScan() {
+	procCache.Lock()
+	defer procCache.Unlock()
+	for pid, c := range procCache.entries {
+		if c.lastScan != procCache.scan {
+			delete(procCache.entries, pid)
+		}
+	}
+	slog.Debug("process cache", "hits", procCache.hits, "misses", procCache.misses,
+		"entries", len(procCache.entries), "denied", procCache.denied)
+}
+
+// noteDenied counts a socket whose process details the OS withheld
+func noteDenied() {
+	procCache.Lock()
+	defer procCache.Unlock()
+	procCache.denied++
+}
+
+// PermissionDenied returns how many sockets in the most recent scan had
+// processes gaze wasn't allowed to read, typically other users' processes
+// when not running as root
+func PermissionDenied() int {
+	procCache.Lock()
+	defer procCache.Unlock()
+	return procCache.denied
+}
+
+// lookupProcess returns the cached details for pid, reading them afresh
+// when the PID is new or its start time shows it now belongs to another
+// process
+func lookupProcess(pid int32) (*cachedProc, error) {
+	// Reading the start time is a single stat of the process, far cheaper
+	// than re-resolving its name
+	createTime, err := (&process.Process{Pid: pid}).CreateTime()
+	if err != nil {
+		return nil, err
+	}
+
+	procCache.Lock()
+	defer procCache.Unlock()
+
+	if c, ok := procCache.entries[pid]; ok && c.createTime == createTime {
+		c.lastScan = procCache.scan
+		procCache.hits++
+		return c, nil
+	}
+	procCache.misses++
+
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	shortName, err := p.Name()
+	if err != nil {
+		// Typically a permission error for another user's process
+		slog.Debug("process name lookup failed", "pid", pid, "error", err)
+		shortName = "Unknown"
+		if errors.Is(err, fs.ErrPermission) {
+			procCache.denied++
+		}
+	}
+	user, err := p.Username()
+	if err != nil {
+		slog.Debug("process user lookup failed", "pid", pid, "error", err)
+		user = UnknownUser
+	}
+	cmdline, err := p.Cmdline()
+	if err != nil {
+		slog.Debug("process command line lookup failed", "pid", pid, "error", err)
+	}
+
+	c := &cachedProc{
+		proc:       p,
+		createTime: createTime,
+		name:       resolveProcessName(p, shortName),
+		shortName:  shortName,
+		user:       user,
+		cmdline:    cmdline,
+		canKill:    canSignal(p),
+		lastScan:   procCache.scan,
+	}
+	procCache.entries[pid] = c
+	return c, nil
+}
+
+// exitedDuringScan reports whether a process lookup failed because the
+// process exited after its socket was listed
+func exitedDuringScan(err error) bool {
+	return errors.Is(err, process.ErrorProcessNotRunning) ||
+		errors.Is(err, fs.ErrNotExist) ||
+		errors.Is(err, syscall.ESRCH)
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func TestExitedDuringScan(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"not running", process.ErrorProcessNotRunning, true},
+		{"proc entry gone", fmt.Errorf("open /proc/42/stat: %w", fs.ErrNotExist), true},
+		{"no such process", syscall.ESRCH, true},
+		{"permission", fs.ErrPermission, false},
+		{"other", errors.New("read failed"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitedDuringScan(tt.err); got != tt.want {
+				t.Errorf("exitedDuringScan(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessDetailsDisappearingPID(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not available")
+	}
+	cmd := exec.Command(sleep, "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := int32(cmd.Process.Pid)
+
+	beginProcScan()
+	if d := processDetails(pid, 0, DefaultConfig()); d.name == ExitingProcess {
+		t.Fatalf("running PID %d reported as %s", pid, d.name)
+	}
+	endProcScan()
+
+	// The process exits between listing its socket and reading its
+	// details, with its cache entry still in place
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	beginProcScan()
+	defer endProcScan()
+	d := processDetails(pid, 0, DefaultConfig())
+	if d.name != ExitingProcess || d.shortName != ExitingProcess {
+		t.Errorf("exited PID %d named %q (%q), want %q", pid, d.name, d.shortName, ExitingProcess)
+	}
+	if d.canKill || d.cpuPercent != 0 || d.memoryMB != 0 {
+		t.Errorf("exited PID %d kept details: %+v", pid, d)
+	}
+}
+
+func BenchmarkProcessDetails(b *testing.B) {
+	pid := int32(os.Getpid())
+	benchmarks := []struct {
+		name   string
+		cached bool
+	}{
+		{"cached", true},
+		{"uncached", false},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg := DefaultConfig()
+			b.ReportAllocs()
+			for b.Loop() {
+				if !bm.cached {
+					procCache.Lock()
+					delete(procCache.entries, pid)
+					procCache.Unlock()
+				}
+				beginProcScan()
+				processDetails(pid, 0, cfg)
+				endProcScan()
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessInfo holds process details that are too costly to collect for
+// every port on every scan, for showing one process at a time
+type ProcessInfo struct {
+	Cwd       string // Working directory, "" if unknown
+	Exe       string // Executable path, "" if unknown
+	PPID      int32  // Parent PID, 0 if unknown
+	OpenFiles int    // Open file descriptors, or -1 if unknown
+}
+
+// GetProcessInfo looks up the details of a process. Each detail the OS
+// won't reveal, often for other users' processes, is left unknown rather
+// than failing the lookup.
+func GetProcessInfo(pid int32) (ProcessInfo, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	info := ProcessInfo{OpenFiles: -1}
+	info.Cwd, _ = p.Cwd()
+	info.Exe, _ = p.Exe()
+	info.PPID, _ = p.Ppid()
+	if n, err := p.NumFDs(); err == nil {
+		info.OpenFiles = int(n)
+	}
+	return info, nil
+}
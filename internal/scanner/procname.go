@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// linuxCommLen is the length Linux truncates process names (comm) to
+const linuxCommLen = 15
+
+// resolveProcessName returns a fuller name for a process when its short
+// name looks truncated, preferring the executable's basename and then the
+// first command line token. It falls back to the short name.
+func resolveProcessName(p *process.Process, short string) string {
+	if !looksTruncated(short) {
+		return short
+	}
+
+	if exe, err := p.Exe(); err == nil && exe != "" {
+		if base := filepath.Base(exe); strings.HasPrefix(base, short) {
+			return base
+		}
+	}
+
+	if args, err := p.CmdlineSlice(); err == nil && len(args) > 0 {
+		if base := filepath.Base(args[0]); strings.HasPrefix(base, short) {
+			return base
+		}
+	}
+
+	return short
+}
+
+// looksTruncated reports whether a process name may have been cut short
+// by the kernel
+func looksTruncated(name string) bool {
+	return runtime.GOOS == "linux" && len(name) == linuxCommLen
+}
@@ -0,0 +1,259 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyCacheTTL is how long discovered upstreams are reused before the
+// proxy's configuration is read again
+const proxyCacheTTL = 30 * time.Second
+
+// Default ports of the admin APIs queried for proxies that expose their
+// live configuration. An API is only queried when the proxy itself
+// listens on its port, as 8080 in particular is often a dev server.
+const (
+	caddyAdminPort   = 2019
+	traefikAdminPort = 8080
+)
+
+// proxyTimeout bounds each attempt to read a proxy's configuration
+const proxyTimeout = 2 * time.Second
+
+// proxyReader lists a reverse proxy's upstreams. It checks its own
+// prerequisites and returns an error when the configuration can't be read.
+type proxyReader struct {
+	read      func() ([]string, error)
+	adminPort int // Port the proxy must listen on to be read, or 0
+}
+
+// proxyReaders maps reverse proxy process names to their readers
+var proxyReaders = map[string]proxyReader{
+	"nginx":   {read: nginxUpstreams},
+	"caddy":   {read: caddyUpstreams, adminPort: caddyAdminPort},
+	"traefik": {read: traefikUpstreams, adminPort: traefikAdminPort},
+}
+
+// proxyCache remembers upstreams per proxy so configuration isn't re-read
+// on every scan. A proxy being read is marked so that concurrent scans
+// use the previous upstreams rather than wait for the read.
+var proxyCache struct {
+	sync.Mutex
+	entries map[string]proxyCacheEntry
+	reading map[string]bool
+}
+
+type proxyCacheEntry struct {
+	upstreams []string
+	updated   time.Time
+}
+
+// addProxyUpstreams fills in the upstreams of the reverse proxies among
+// the scanned ports
+func addProxyUpstreams(ports map[socketKey]PortInfo) {
+	held := make(map[int32][]int)
+	for _, p := range ports {
+		held[p.PID] = append(held[p.PID], p.Port)
+	}
+	for key, p := range ports {
+		if p.PID == 0 {
+			continue
+		}
+		if upstreams := getProxyUpstreams(p.Process, held[p.PID]); upstreams != nil {
+			p.Upstreams = upstreams
+			ports[key] = p
+		}
+	}
+}
+
+// getProxyUpstreams returns the upstream targets of a reverse proxy
+// process listening on ports, or nil if it isn't a known proxy or its
+// configuration can't be read. Detection is best-effort and failures are
+// only logged.
+func getProxyUpstreams(process string, ports []int) []string {
+	kind := proxyKind(process)
+	reader, ok := proxyReaders[kind]
+	if !ok || (reader.adminPort != 0 && !slices.Contains(ports, reader.adminPort)) {
+		return nil
+	}
+
+	proxyCache.Lock()
+	e, cached := proxyCache.entries[kind]
+	if (cached && time.Since(e.updated) < proxyCacheTTL) || proxyCache.reading[kind] {
+		proxyCache.Unlock()
+		return e.upstreams
+	}
+	if proxyCache.reading == nil {
+		proxyCache.reading = make(map[string]bool)
+	}
+	proxyCache.reading[kind] = true
+	proxyCache.Unlock()
+
+	// Reading may take seconds, e.g. running nginx -T, so it happens
+	// outside the lock
+	upstreams, err := reader.read()
+	if err != nil {
+		slog.Debug("reading proxy upstreams failed", "proxy", kind, "error", err)
+	}
+
+	proxyCache.Lock()
+	defer proxyCache.Unlock()
+	delete(proxyCache.reading, kind)
+	if proxyCache.entries == nil {
+		proxyCache.entries = make(map[string]proxyCacheEntry)
+	}
+	// Failures are cached too so an unreadable config isn't retried every scan
+	proxyCache.entries[kind] = proxyCacheEntry{upstreams: upstreams, updated: time.Now()}
+	return upstreams
+}
+
+// proxyKind normalizes a process name such as "nginx.exe" to its proxy key
+func proxyKind(process string) string {
+	return strings.TrimSuffix(strings.ToLower(process), ".exe")
+}
+
+var (
+	nginxUpstreamBlock = regexp.MustCompile(`(?s)upstream\s+(\S+)\s*\{(.*?)\}`)
+	nginxServer        = regexp.MustCompile(`(?m)^\s*server\s+([^\s;]+)`)
+	nginxProxyPass     = regexp.MustCompile(`(?m)^\s*(?:proxy|fastcgi|uwsgi|grpc)_pass\s+([^\s;]+)`)
+)
+
+// nginxUpstreams lists proxy targets from `nginx -T`, expanding named
+// upstream blocks into their servers
+func nginxUpstreams() ([]string, error) {
+	if _, err := exec.LookPath("nginx"); err != nil {
+		return nil, err
+	}
+	out, err := runCommand(context.Background(), commandTimeout, "nginx -T", "nginx", "-T")
+	if errors.Is(err, ErrCommandTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nginx -T failed: %w", err)
+	}
+	return parseNginxUpstreams(string(out)), nil
+}
+
+// parseNginxUpstreams extracts proxy targets from a dumped nginx config
+func parseNginxUpstreams(conf string) []string {
+	groups := make(map[string][]string)
+	for _, m := range nginxUpstreamBlock.FindAllStringSubmatch(conf, -1) {
+		for _, s := range nginxServer.FindAllStringSubmatch(m[2], -1) {
+			groups[m[1]] = append(groups[m[1]], s[1])
+		}
+	}
+
+	var targets []string
+	for _, m := range nginxProxyPass.FindAllStringSubmatch(conf, -1) {
+		target := m[1]
+		// proxy_pass http://backend; refers to an upstream block by name
+		name := target
+		if _, rest, ok := strings.Cut(target, "://"); ok {
+			name, _, _ = strings.Cut(rest, "/")
+		}
+		if servers, ok := groups[name]; ok {
+			targets = append(targets, servers...)
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return uniqueSorted(targets)
+}
+
+// caddyUpstreams lists reverse_proxy dial addresses from Caddy's admin API
+func caddyUpstreams() ([]string, error) {
+	var conf any
+	if err := getJSON(fmt.Sprintf("http://localhost:%d/config/", caddyAdminPort), &conf); err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	walkJSON(conf, func(key string, v any) {
+		if key != "dial" {
+			return
+		}
+		if s, ok := v.(string); ok {
+			targets = append(targets, s)
+		}
+	})
+	return uniqueSorted(targets), nil
+}
+
+// traefikUpstreams lists load balancer servers from Traefik's API, which
+// is only available when the API is enabled on its default port
+func traefikUpstreams() ([]string, error) {
+	var services []struct {
+		LoadBalancer struct {
+			Servers []struct {
+				URL string `json:"url"`
+			} `json:"servers"`
+		} `json:"loadBalancer"`
+	}
+	if err := getJSON(fmt.Sprintf("http://localhost:%d/api/http/services", traefikAdminPort), &services); err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	for _, svc := range services {
+		for _, s := range svc.LoadBalancer.Servers {
+			targets = append(targets, s.URL)
+		}
+	}
+	return uniqueSorted(targets), nil
+}
+
+// getJSON fetches a local admin endpoint and decodes its JSON response
+func getJSON(url string, v any) error {
+	client := &http.Client{Timeout: proxyTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// walkJSON calls fn for every key/value pair in a decoded JSON document
+func walkJSON(v any, fn func(key string, v any)) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			fn(key, child)
+			walkJSON(child, fn)
+		}
+	case []any:
+		for _, child := range v {
+			walkJSON(child, fn)
+		}
+	}
+}
+
+// uniqueSorted returns the distinct strings in s, sorted
+func uniqueSorted(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	sort.Strings(s)
+	out := s[:1]
+	for _, v := range s[1:] {
+		if v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
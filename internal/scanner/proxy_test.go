@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// withProxyReader replaces the readers with one for kind for the test,
+// starting from an empty cache
+func withProxyReader(t *testing.T, kind string, reader proxyReader) {
+	saved := proxyReaders
+	proxyReaders = map[string]proxyReader{kind: reader}
+	proxyCache.entries, proxyCache.reading = nil, nil
+	t.Cleanup(func() {
+		proxyReaders = saved
+		proxyCache.entries, proxyCache.reading = nil, nil
+	})
+}
+
+func TestGetProxyUpstreamsAdminPort(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports []int
+		want  []string
+	}{
+		{"proxy holds its API port", []int{80, 8080}, []string{"http://10.0.0.1:80"}},
+		{"another process holds the port", []int{80, 443}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withProxyReader(t, "traefik", proxyReader{
+				read:      func() ([]string, error) { return []string{"http://10.0.0.1:80"}, nil },
+				adminPort: 8080,
+			})
+			if got := getProxyUpstreams("traefik", tt.ports); !slices.Equal(got, tt.want) {
+				t.Errorf("getProxyUpstreams = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetProxyUpstreamsDoesNotWaitForRead(t *testing.T) {
+	release := make(chan struct{})
+	withProxyReader(t, "nginx", proxyReader{read: func() ([]string, error) {
+		<-release
+		return []string{"127.0.0.1:3000"}, nil
+	}})
+
+	first := make(chan []string)
+	go func() { first <- getProxyUpstreams("nginx", nil) }()
+	// Wait for the first call to start reading
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		proxyCache.Lock()
+		reading := proxyCache.reading["nginx"]
+		proxyCache.Unlock()
+		if reading {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("read never started")
+		}
+	}
+
+	done := make(chan []string)
+	go func() { done <- getProxyUpstreams("nginx", nil) }()
+	select {
+	case got := <-done:
+		if got != nil {
+			t.Errorf("upstreams during the first read = %v, want none yet", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a concurrent call waited for the read")
+	}
+
+	close(release)
+	if got := <-first; !slices.Equal(got, []string{"127.0.0.1:3000"}) {
+		t.Errorf("upstreams = %v", got)
+	}
+	if got := getProxyUpstreams("nginx", nil); !slices.Equal(got, []string{"127.0.0.1:3000"}) {
+		t.Errorf("cached upstreams = %v", got)
+	}
+}
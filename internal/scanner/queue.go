@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// QueueUnknown is the QueueDepth of ports whose accept queue can't be read
+const QueueUnknown = -1
+
+// tcpListenState is the hex socket state /proc/net/tcp uses for LISTEN
+const tcpListenState = "0A"
+
+// getQueueDepths returns the accept queue depth (Recv-Q, as shown by ss)
+// of every listening TCP port. For a listening socket the receive queue
+// counts connections waiting to be accepted. It returns nil on non-Linux
+// systems, where the information isn't exposed.
+func getQueueDepths() map[int]int {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+
+	depths := make(map[int]int)
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		readQueueDepths(path, depths)
+	}
+	return depths
+}
+
+// readQueueDepths adds the listening sockets in a /proc/net/tcp style file
+// to depths, keeping the deepest queue when a port is bound more than once
+func readQueueDepths(path string, depths map[int]int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	lines.Scan() // Header
+	for lines.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue ...
+		fields := strings.Fields(lines.Text())
+		if len(fields) < 5 || fields[3] != tcpListenState {
+			continue
+		}
+
+		_, portHex, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseInt(portHex, 16, 32)
+		if err != nil {
+			continue
+		}
+		_, rxHex, ok := strings.Cut(fields[4], ":")
+		if !ok {
+			continue
+		}
+		rx, err := strconv.ParseInt(rxHex, 16, 64)
+		if err != nil {
+			continue
+		}
+
+		if current, seen := depths[int(port)]; !seen || int(rx) > current {
+			depths[int(port)] = int(rx)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package scanner
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/net"
@@ -12,81 +21,371 @@ import (
 
 // PortInfo represents information about a listening port
 type PortInfo struct {
-	Port       int
-	PID        int32
-	Process    string
-	Status     string
-	HTTPStatus int           // HTTP response status code (0 if not checked)
-	Latency    time.Duration // Response latency
-	CPUPercent float64       // CPU usage percentage
-	MemoryMB   float64       // Memory usage in MB
-	Selected   bool          // For multi-select mode
+	Port           int
+	PID            int32
+	Process        string // Resolved process name
+	ShortName      string // Name as reported by the OS, possibly truncated
+	User           string // User owning the process, SystemUser for PID 0 or UnknownUser
+	Cmdline        string // Full command line, e.g. "node server.js --port 3000"; "" if unknown
+	Status         string
+	ProcState      string         // Scheduler state from gopsutil, e.g. "running", ProcStopped
+	SocketType     string         // SocketTCP, SocketUDP or SocketUnix
+	SocketPath     string         // Filesystem path of a unix socket; Port is 0 for these
+	ListenAddr     string         // Local address the socket is bound to, e.g. "127.0.0.1"
+	HTTPStatus     int            // HTTP response status code (0 if not checked)
+	Latency        time.Duration  // Response latency
+	DetectedServer string         // Server/X-Powered-By headers from the HTTP check
+	CPUPercent     float64        // CPU usage percentage
+	MemoryMB       float64        // Memory usage in MB
+	Niceness       int32          // Scheduling priority (Unix nice value)
+	Selected       bool           // For multi-select mode
+	CanKill        bool           // Whether gaze has permission to kill the process
+	NetNS          string         // Network namespace, e.g. "net:[4026531840]" (Linux only)
+	ServiceName    string         // Windows services hosted by the process, e.g. "Dnscache" (Windows only)
+	WellKnown      string         // Service registered for the port number, e.g. "postgresql" for 5432
+	Upstreams      []string       // Targets a reverse proxy forwards to, if detected
+	QueueDepth     int            // Connections waiting to be accepted, or QueueUnknown
+	ConnStates     map[string]int // Non-listening TCP sockets on the port by state, e.g. "CLOSE_WAIT"
+	Connections    int            // ESTABLISHED TCP connections on the port; 0 for UDP and unix sockets
+	NumThreads     int32          // Thread count, or -1 if not collected or unavailable
+	StartTime      time.Time      // When the process started, zero if unknown
+
+	// Container details, set when the port is published by a Docker container
+	IsContainer    bool
+	ContainerName  string
+	ContainerImage string
+}
+
+// Endpoint identifies the socket for display: the port number, or the
+// path of a unix socket
+func (p PortInfo) Endpoint() string {
+	if p.SocketType == SocketUnix {
+		return p.SocketPath
+	}
+	return fmt.Sprintf("%d", p.Port)
+}
+
+// Users reported for processes whose owner can't be looked up
+const (
+	SystemUser  = "System"  // PID 0, like GetProcessName
+	UnknownUser = "Unknown" // The lookup failed, e.g. the process exited
+)
+
+// ExitingProcess is the process name of ports whose process exited while
+// the scan was gathering its details
+const ExitingProcess = "(exiting)"
+
+// HTTP health check tuning
+const (
+	maxHTTPRedirects = 3
+	httpRetryDelay   = 100 * time.Millisecond
+	// Winsock's connection refused error, which syscall.ECONNREFUSED
+	// doesn't match on Windows
+	wsaECONNREFUSED syscall.Errno = 10061
+)
+
+// Config controls optional scanner behaviour
+type Config struct {
+	HTTPTimeout time.Duration // Per-request timeout for HTTP health checks
+	// Read each process's thread count. Off by default as it costs an
+	// extra read per process on every scan.
+	CollectThreads bool
+	// Only list ports bound to an address in this prefix, when valid
+	BindCIDR netip.Prefix
+	// Also list listening unix domain sockets (Linux only)
+	IncludeUnix bool
+	// Skip HTTP health checks, for faster scans whose output doesn't
+	// depend on how services answer
+	NoHTTPCheck bool
+	// Ports probed by the HTTP health check, and those of them probed
+	// over HTTPS
+	HTTPPorts []PortRange
+	TLSPorts  []PortRange
+}
+
+// Ports probed by the HTTP health check unless configured otherwise
+var (
+	DefaultHTTPPorts = []PortRange{
+		{80, 80}, {443, 443}, {3000, 3001}, {4200, 4200}, {5000, 5000}, {5173, 5173},
+		{8000, 8000}, {8080, 8080}, {8443, 8443}, {8888, 8888}, {9000, 9000},
+	}
+	DefaultTLSPorts = []PortRange{{443, 443}, {8443, 8443}}
+)
+
+// isWebPort reports whether port gets an HTTP health check
+func (c Config) isWebPort(port int) bool {
+	return inRanges(c.HTTPPorts, port)
+}
+
+// usesTLS reports whether port's health check uses HTTPS
+func (c Config) usesTLS(port int) bool {
+	return inRanges(c.TLSPorts, port)
+}
+
+// WebURL returns the local URL of a web port: one that answered the HTTP
+// health check or gets one. It reports false for other ports.
+func (c Config) WebURL(p PortInfo) (string, bool) {
+	if p.SocketType != SocketTCP || (p.HTTPStatus == 0 && !c.isWebPort(p.Port)) {
+		return "", false
+	}
+	scheme := "http"
+	if c.usesTLS(p.Port) {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://localhost:%d", scheme, p.Port), true
+}
+
+// inRanges reports whether port falls within any of ranges
+func inRanges(ranges []PortRange, port int) bool {
+	for _, r := range ranges {
+		if r.Contains(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultConfig returns the default scanner configuration
+func DefaultConfig() Config {
+	return Config{
+		HTTPTimeout: 1 * time.Second,
+		HTTPPorts:   DefaultHTTPPorts,
+		TLSPorts:    DefaultTLSPorts,
+	}
 }
 
 // ScanPorts scans for all active network connections
-func ScanPorts() ([]PortInfo, error) {
+func ScanPorts(cfg Config) ([]PortInfo, error) {
 	conns, err := net.Connections("inet")
 	if err != nil {
+		slog.Error("listing connections failed", "error", err)
 		return nil, fmt.Errorf("failed to get connections: %w", err)
 	}
 
-	// Use a map to deduplicate ports with the same PID
-	portMap := make(map[int]PortInfo)
+	beginProcScan()
+	defer endProcScan()
+
+	// Use a map to deduplicate ports with the same PID. TCP and UDP
+	// ports with the same number are different sockets, as are sockets
+	// bound to different addresses, e.g. 127.0.0.1 and ::1.
+	portMap := make(map[socketKey]PortInfo)
+	containers := getContainerInfo()
+	queues := getQueueDepths()
+	states := countConnStates(conns)
 
 	for _, conn := range conns {
-		if conn.Laddr.Port != 0 && conn.Status == "LISTEN" {
+		if socketType, listening := listeningType(conn); listening {
 			port := int(conn.Laddr.Port)
+			key := socketKey{socketType, conn.Laddr.IP, port}
 
 			// Skip if already have this port
-			if _, exists := portMap[port]; exists {
+			if _, exists := portMap[key]; exists {
 				continue
 			}
-
-			pName := "Unknown"
-			var cpuPercent, memoryMB float64
-			if conn.Pid != 0 {
-				p, err := process.NewProcess(conn.Pid)
-				if err == nil {
-					pName, _ = p.Name()
-					// Get CPU and memory usage
-					cpuPercent, _ = p.CPUPercent()
-					memInfo, err := p.MemoryInfo()
-					if err == nil {
-						memoryMB = float64(memInfo.RSS) / 1024 / 1024
-					}
-				}
+			if !boundWithin(conn.Laddr.IP, cfg.BindCIDR) {
+				continue
 			}
 
+			proc := processDetails(conn.Pid, port, cfg)
 			portInfo := PortInfo{
-				Port:       port,
-				PID:        conn.Pid,
-				Process:    pName,
-				Status:     conn.Status,
-				CPUPercent: cpuPercent,
-				MemoryMB:   memoryMB,
+				Port:        port,
+				PID:         conn.Pid,
+				Process:     proc.name,
+				ShortName:   proc.shortName,
+				User:        proc.user,
+				Cmdline:     proc.cmdline,
+				Status:      conn.Status,
+				ProcState:   proc.state,
+				SocketType:  socketType,
+				ListenAddr:  conn.Laddr.IP,
+				CPUPercent:  proc.cpuPercent,
+				MemoryMB:    proc.memoryMB,
+				Niceness:    proc.niceness,
+				NetNS:       getNetNS(conn.Pid),
+				ServiceName: getWindowsServices(conn.Pid),
+				WellKnown:   WellKnownService(port),
+				QueueDepth:  QueueUnknown,
+				NumThreads:  proc.numThreads,
+				StartTime:   proc.startTime,
+				CanKill:     proc.canKill,
+			}
+			if socketType == SocketUDP {
+				// UDP has no listening state to report
+				portInfo.Status = udpStatus
+			} else {
+				portInfo.ConnStates = states[port]
+				portInfo.Connections = states[port]["ESTABLISHED"]
+				if depth, ok := queues[port]; ok {
+					portInfo.QueueDepth = depth
+				}
 			}
 
-			// Check HTTP health for common web ports
-			if isWebPort(port) {
-				statusCode, latency := checkHTTPHealth(port)
-				portInfo.HTTPStatus = statusCode
-				portInfo.Latency = latency
+			if c, ok := containers[port]; ok {
+				portInfo.IsContainer = true
+				portInfo.ContainerName = c.Name
+				portInfo.ContainerImage = c.Image
 			}
 
-			portMap[port] = portInfo
+			portMap[key] = portInfo
 		}
 	}
 
+	addProxyUpstreams(portMap)
+
+	// Check HTTP health for common web ports
+	if !cfg.NoHTTPCheck {
+		checkWebPorts(portMap, cfg)
+	}
+
 	// Convert map to slice
 	var results []PortInfo
 	for _, info := range portMap {
 		results = append(results, info)
 	}
+	if cfg.IncludeUnix {
+		results = append(results, scanUnixSockets(cfg)...)
+	}
 
 	return results, nil
 }
 
-// KillProcess kills a process by its PID
+// socketKey identifies a listening socket in a scan
+type socketKey struct {
+	socketType string
+	addr       string
+	port       int
+}
+
+// udpStatus is the Status of UDP ports, which have no LISTEN state
+const udpStatus = "BOUND"
+
+// listeningType returns the socket type of conn and whether it is
+// listening: a TCP socket in LISTEN, or a bound UDP socket without a
+// peer. UDP has no listening state, so any unconnected bound socket can
+// receive datagrams and counts as listening.
+func listeningType(conn net.ConnectionStat) (string, bool) {
+	if conn.Laddr.Port == 0 {
+		return "", false
+	}
+	switch conn.Type {
+	case syscall.SOCK_STREAM:
+		return SocketTCP, conn.Status == "LISTEN"
+	case syscall.SOCK_DGRAM:
+		return SocketUDP, conn.Raddr.Port == 0
+	}
+	return "", false
+}
+
+// procDetails are the details of the process holding a socket
+type procDetails struct {
+	name, shortName string
+	user, cmdline   string
+	cpuPercent      float64
+	memoryMB        float64
+	niceness        int32
+	numThreads      int32
+	startTime       time.Time
+	canKill         bool
+	state           string
+}
+
+// processDetails reads the details of pid for the socket identified by
+// port, which is only used for logging
+func processDetails(pid int32, port any, cfg Config) procDetails {
+	d := procDetails{name: "Unknown", shortName: "Unknown", user: UnknownUser, numThreads: -1}
+	if pid == 0 {
+		// Linux hides the owners of other users' sockets from non-root
+		// users, which leaves them with PID 0
+		if runtime.GOOS == "linux" && os.Geteuid() != 0 {
+			noteDenied()
+		}
+		d.user = SystemUser
+		return d
+	}
+
+	c, err := lookupProcess(pid)
+	switch {
+	case err != nil && exitedDuringScan(err):
+		// The socket is about to go away with its process
+		slog.Debug("process exited during scan", "port", port, "pid", pid)
+		d.name, d.shortName = ExitingProcess, ExitingProcess
+	case err != nil:
+		slog.Debug("process lookup failed", "port", port, "pid", pid, "error", err)
+		if errors.Is(err, fs.ErrPermission) {
+			noteDenied()
+		}
+	default:
+		d.name, d.shortName = c.name, c.shortName
+		d.user, d.cmdline = c.user, c.cmdline
+		d.canKill = c.canKill
+		d.startTime = time.UnixMilli(c.createTime)
+		// CPU, memory and priority change while the process runs, so they
+		// are read on every scan
+		d.cpuPercent, _ = c.proc.CPUPercent()
+		if memInfo, err := c.proc.MemoryInfo(); err == nil {
+			d.memoryMB = float64(memInfo.RSS) / 1024 / 1024
+		}
+		d.niceness, _ = processNiceness(c.proc)
+		if states, err := c.proc.Status(); err == nil && len(states) > 0 {
+			d.state = states[0]
+		}
+		if cfg.CollectThreads {
+			if n, err := c.proc.NumThreads(); err == nil {
+				d.numThreads = n
+			}
+		}
+	}
+	return d
+}
+
+// connRefused reports whether err is a refused connection, by the errno
+// the platform uses for it
+func connRefused(err error) bool {
+	if runtime.GOOS == "windows" {
+		return errors.Is(err, wsaECONNREFUSED)
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// CloseWaitWarnThreshold is the number of CLOSE_WAIT sockets on a port
+// above which the owning process is probably not closing its connections
+const CloseWaitWarnThreshold = 10
+
+// countConnStates tallies the non-listening TCP sockets on each local port
+// by state. Sockets accepted by a listener share its local port, so this
+// shows e.g. CLOSE_WAIT sockets piling up behind a server.
+func countConnStates(conns []net.ConnectionStat) map[int]map[string]int {
+	counts := make(map[int]map[string]int)
+	for _, conn := range conns {
+		if conn.Laddr.Port == 0 || conn.Status == "LISTEN" || conn.Status == "" || conn.Status == "NONE" {
+			continue
+		}
+		port := int(conn.Laddr.Port)
+		if counts[port] == nil {
+			counts[port] = make(map[string]int)
+		}
+		counts[port][conn.Status]++
+	}
+	return counts
+}
+
+// IsPortListening reports whether any process is listening on a TCP port.
+// It is cheaper than a full scan as no process details are gathered.
+func IsPortListening(port int) (bool, error) {
+	conns, err := net.Connections("inet")
+	if err != nil {
+		return false, fmt.Errorf("failed to get connections: %w", err)
+	}
+	for _, conn := range conns {
+		if int(conn.Laddr.Port) == port && conn.Status == "LISTEN" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// KillProcess kills a process by its PID. Failures to signal the process
+// are returned as a *KillError describing the cause.
 func KillProcess(pid int32) error {
 	if pid == 0 {
 		return fmt.Errorf("invalid PID: 0")
@@ -94,13 +393,47 @@ func KillProcess(pid int32) error {
 
 	p, err := os.FindProcess(int(pid))
 	if err != nil {
-		return fmt.Errorf("failed to find process: %w", err)
+		return classifyKillError(pid, err)
 	}
 
 	err = p.Kill()
 	if err != nil {
-		return fmt.Errorf("failed to kill process: %w", err)
+		slog.Warn("kill failed", "pid", pid, "error", err)
+		return classifyKillError(pid, err)
 	}
+	slog.Info("killed process", "pid", pid)
+
+	return nil
+}
+
+// SignalsSupported reports whether KillProcessWithSignal can deliver a
+// chosen signal on this platform. Windows has no signals, so there it
+// always terminates the process outright.
+func SignalsSupported() bool {
+	return runtime.GOOS != "windows"
+}
+
+// KillProcessWithSignal sends sig to a process, e.g. syscall.SIGTERM to
+// let it shut down cleanly. Where signals aren't supported it falls back
+// to KillProcess. Failures are returned as a *KillError.
+func KillProcessWithSignal(pid int32, sig syscall.Signal) error {
+	if pid == 0 {
+		return fmt.Errorf("invalid PID: 0")
+	}
+	if !SignalsSupported() {
+		return KillProcess(pid)
+	}
+
+	p, err := os.FindProcess(int(pid))
+	if err != nil {
+		return classifyKillError(pid, err)
+	}
+
+	if err := p.Signal(sig); err != nil {
+		slog.Warn("signal failed", "pid", pid, "signal", sig, "error", err)
+		return classifyKillError(pid, err)
+	}
+	slog.Info("signalled process", "pid", pid, "signal", sig)
 
 	return nil
 }
@@ -121,7 +454,7 @@ func GetProcessName(pid int32) string {
 		return "Unknown"
 	}
 
-	return name
+	return resolveProcessName(p, name)
 }
 
 // GetPortType categorizes a port into well-known, registered, or dynamic
@@ -134,46 +467,144 @@ func GetPortType(port int) string {
 	return "dynamic"
 }
 
-// isWebPort checks if a port is commonly used for web services
-func isWebPort(port int) bool {
-	commonWebPorts := []int{80, 443, 8080, 8000, 8443, 3000, 5000, 3001, 4200, 5173, 8888, 9000}
-	for _, p := range commonWebPorts {
-		if port == p {
-			return true
+// checkWebPorts runs the HTTP health checks of the TCP web ports in ports
+// and records the results. Each check can take up to the timeout, so they
+// run concurrently and a scan waits only for the slowest one. A port
+// bound to several addresses is checked once.
+func checkWebPorts(ports map[socketKey]PortInfo, cfg Config) {
+	var web []int
+	seen := make(map[int]bool)
+	for key, info := range ports {
+		if key.socketType != SocketTCP || !cfg.isWebPort(key.port) || info.Process == ExitingProcess || seen[key.port] {
+			continue
 		}
+		seen[key.port] = true
+		web = append(web, key.port)
+	}
+
+	results := make([]HTTPResult, len(web))
+	var wg sync.WaitGroup
+	for i, port := range web {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkHTTPHealth(port, cfg.usesTLS(port), cfg.HTTPTimeout)
+		}()
+	}
+	wg.Wait()
+
+	byPort := make(map[int]HTTPResult, len(web))
+	for i, port := range web {
+		byPort[port] = results[i]
+	}
+	for key, info := range ports {
+		result, checked := byPort[key.port]
+		if !checked || key.socketType != SocketTCP {
+			continue
+		}
+		info.HTTPStatus = result.StatusCode
+		info.Latency = result.Latency
+		info.DetectedServer = result.Server
+		ports[key] = info
 	}
-	return false
 }
 
-// checkHTTPHealth performs HTTP health check with latency measurement
-func checkHTTPHealth(port int) (int, time.Duration) {
+// HTTPResult is the outcome of an HTTP health check
+type HTTPResult struct {
+	StatusCode int           // 0 if the port didn't answer HTTP
+	Latency    time.Duration // Time to receive the response
+	Server     string        // Stack reported by the Server/X-Powered-By headers
+}
+
+// checkHTTPHealth performs HTTP health check with latency measurement.
+// Redirects are followed up to a small limit so an app answering 301→200
+// reports 200, and a refused connection is retried once to smooth over
+// servers that are still starting up. With useTLS the check uses HTTPS
+// without verifying the certificate, as local services commonly use
+// self-signed ones.
+func checkHTTPHealth(port int, useTLS bool, timeout time.Duration) HTTPResult {
+	scheme := "http"
+	transport := http.DefaultTransport
+	if useTLS {
+		scheme = "https"
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		transport = t
+	}
 	client := &http.Client{
-		Timeout: 1 * time.Second,
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxHTTPRedirects {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
 	}
 
-	url := fmt.Sprintf("http://localhost:%d", port)
+	url := fmt.Sprintf("%s://localhost:%d", scheme, port)
 	start := time.Now()
 	resp, err := client.Get(url)
+	if err != nil && connRefused(err) {
+		time.Sleep(httpRetryDelay)
+		start = time.Now()
+		resp, err = client.Get(url)
+	}
 	latency := time.Since(start)
 
 	if err != nil {
-		return 0, 0
+		slog.Debug("HTTP health check failed", "port", port, "error", err)
+		return HTTPResult{}
 	}
 	defer resp.Body.Close()
 
-	return resp.StatusCode, latency
+	return HTTPResult{
+		StatusCode: resp.StatusCode,
+		Latency:    latency,
+		Server:     detectServer(resp.Header),
+	}
+}
+
+// detectServer describes the server stack from the response headers,
+// e.g. "nginx/1.25.3 (Express)". It returns "" when neither is present.
+func detectServer(h http.Header) string {
+	server := strings.TrimSpace(h.Get("Server"))
+	poweredBy := strings.TrimSpace(h.Get("X-Powered-By"))
+	switch {
+	case server != "" && poweredBy != "":
+		return fmt.Sprintf("%s (%s)", server, poweredBy)
+	case server != "":
+		return server
+	}
+	return poweredBy
+}
+
+// CheckHTTPHealth performs a one-off HTTP health check against a local
+// port, over HTTPS if it is one of cfg's TLS ports
+func CheckHTTPHealth(port int, cfg Config) HTTPResult {
+	return checkHTTPHealth(port, cfg.usesTLS(port), cfg.HTTPTimeout)
 }
 
-// KillMultipleProcesses kills multiple processes by their PIDs
-func KillMultipleProcesses(pids []int32) error {
-	var errors []error
+// KillMultipleProcesses sends sig to multiple processes by their PIDs, as
+// KillProcessWithSignal does. The result maps each PID to its error, or
+// nil if it was signalled successfully.
+func KillMultipleProcesses(pids []int32, sig syscall.Signal) map[int32]error {
+	results := make(map[int32]error, len(pids))
 	for _, pid := range pids {
-		if err := KillProcess(pid); err != nil {
-			errors = append(errors, err)
+		if _, done := results[pid]; done {
+			continue
 		}
+		results[pid] = KillProcessWithSignal(pid, sig)
 	}
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to kill %d processes", len(errors))
+	return results
+}
+
+// CountProcesses returns how many distinct processes hold the ports, as a
+// process listening on several ports is only killed once
+func CountProcesses(ports []PortInfo) int {
+	pids := make(map[int32]bool, len(ports))
+	for _, p := range ports {
+		pids[p.PID] = true
 	}
-	return nil
+	return len(pids)
 }
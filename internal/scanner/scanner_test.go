@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestConnRefused(t *testing.T) {
+	// Find a port nothing listens on by closing a listener
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen on localhost:", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	_, dialErr := net.Dial("tcp", addr)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dial to a closed port", dialErr, true},
+		{"wrapped", fmt.Errorf("get: %w", dialErr), true},
+		{"reset", os.NewSyscallError("read", syscall.ECONNRESET), false},
+		{"other", errors.New("timeout"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := connRefused(tt.err); got != tt.want {
+				t.Errorf("connRefused(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
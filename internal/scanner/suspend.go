@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Process states of PortInfo.ProcState worth flagging
+const (
+	ProcStopped = process.Stop
+	ProcZombie  = process.Zombie
+)
+
+// CanSuspend reports whether processes can be stopped and continued on
+// this platform, which needs SIGSTOP and SIGCONT
+func CanSuspend() bool {
+	return runtime.GOOS != "windows"
+}
+
+// SuspendProcess stops a process with SIGSTOP until it is resumed
+func SuspendProcess(pid int32) error {
+	if pid == 0 {
+		return fmt.Errorf("invalid PID: 0")
+	}
+	if !CanSuspend() {
+		return fmt.Errorf("suspending processes is not supported on %s", runtime.GOOS)
+	}
+	if err := (&process.Process{Pid: pid}).Suspend(); err != nil {
+		slog.Warn("suspend failed", "pid", pid, "error", err)
+		return fmt.Errorf("failed to suspend PID %d: %w", pid, err)
+	}
+	slog.Info("suspended process", "pid", pid)
+	return nil
+}
+
+// ResumeProcess continues a stopped process with SIGCONT
+func ResumeProcess(pid int32) error {
+	if pid == 0 {
+		return fmt.Errorf("invalid PID: 0")
+	}
+	if !CanSuspend() {
+		return fmt.Errorf("resuming processes is not supported on %s", runtime.GOOS)
+	}
+	if err := (&process.Process{Pid: pid}).Resume(); err != nil {
+		slog.Warn("resume failed", "pid", pid, "error", err)
+		return fmt.Errorf("failed to resume PID %d: %w", pid, err)
+	}
+	slog.Info("resumed process", "pid", pid)
+	return nil
+}
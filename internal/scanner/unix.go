@@ -0,0 +1,107 @@
+package scanner
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Socket types of PortInfo.SocketType
+const (
+	SocketTCP  = "tcp"
+	SocketUDP  = "udp"
+	SocketUnix = "unix"
+)
+
+// unixAcceptFlag is the /proc/net/unix flag (__SO_ACCEPTCON) set on
+// listening sockets
+const unixAcceptFlag = 0x10000
+
+// scanUnixSockets lists the listening unix domain sockets that have a
+// path. gopsutil doesn't report whether a unix socket is listening, so
+// /proc/net/unix is read directly and gopsutil only supplies the owning
+// PIDs. It returns nil on non-Linux systems.
+func scanUnixSockets(cfg Config) []PortInfo {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+
+	paths := readListeningUnixPaths("/proc/net/unix")
+	if len(paths) == 0 {
+		return nil
+	}
+
+	owners := make(map[string]int32)
+	conns, err := net.Connections("unix")
+	if err != nil {
+		slog.Debug("listing unix sockets failed", "error", err)
+	}
+	for _, conn := range conns {
+		if conn.Pid != 0 && conn.Laddr.IP != "" {
+			owners[conn.Laddr.IP] = conn.Pid
+		}
+	}
+
+	var results []PortInfo
+	for _, path := range paths {
+		pid := owners[path]
+		proc := processDetails(pid, path, cfg)
+		results = append(results, PortInfo{
+			PID:        pid,
+			Process:    proc.name,
+			ShortName:  proc.shortName,
+			User:       proc.user,
+			Cmdline:    proc.cmdline,
+			Status:     "LISTEN",
+			ProcState:  proc.state,
+			SocketType: SocketUnix,
+			SocketPath: path,
+			CPUPercent: proc.cpuPercent,
+			MemoryMB:   proc.memoryMB,
+			Niceness:   proc.niceness,
+			NetNS:      getNetNS(pid),
+			QueueDepth: QueueUnknown,
+			NumThreads: proc.numThreads,
+			StartTime:  proc.startTime,
+			CanKill:    proc.canKill,
+		})
+	}
+	return results
+}
+
+// readListeningUnixPaths returns the distinct paths of the listening
+// sockets in a /proc/net/unix style file. Abstract sockets are shown with
+// their leading @.
+func readListeningUnixPaths(file string) []string {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	seen := make(map[string]bool)
+	var paths []string
+	lines := bufio.NewScanner(f)
+	lines.Scan() // Header
+	for lines.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		fields := strings.Fields(lines.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&unixAcceptFlag == 0 {
+			continue
+		}
+		if path := fields[7]; !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// servicesFile is the system's registry of port numbers to service names
+const servicesFile = "/etc/services"
+
+// fallbackServices names common TCP ports on systems without a services
+// file, such as Windows. Names follow the IANA registry.
+var fallbackServices = map[int]string{
+	20:    "ftp-data",
+	21:    "ftp",
+	22:    "ssh",
+	23:    "telnet",
+	25:    "smtp",
+	53:    "domain",
+	80:    "http",
+	110:   "pop3",
+	111:   "sunrpc",
+	135:   "epmap",
+	139:   "netbios-ssn",
+	143:   "imap",
+	389:   "ldap",
+	443:   "https",
+	445:   "microsoft-ds",
+	465:   "submissions",
+	587:   "submission",
+	631:   "ipp",
+	636:   "ldaps",
+	873:   "rsync",
+	993:   "imaps",
+	995:   "pop3s",
+	1433:  "ms-sql-s",
+	1883:  "mqtt",
+	2049:  "nfs",
+	2375:  "docker",
+	2376:  "docker-s",
+	2379:  "etcd-client",
+	3306:  "mysql",
+	3389:  "ms-wbt-server",
+	5432:  "postgresql",
+	5672:  "amqp",
+	5900:  "rfb",
+	6379:  "redis",
+	8080:  "http-alt",
+	9092:  "kafka",
+	11211: "memcache",
+	27017: "mongodb",
+}
+
+// wellKnown holds the TCP port registry, loaded on first use
+var wellKnown struct {
+	sync.Once
+	services map[int]string
+}
+
+// WellKnownService returns the service name registered for a TCP port,
+// e.g. "postgresql" for 5432, or "" if it has none. This is what the port
+// is conventionally used for, not what actually listens on it.
+func WellKnownService(port int) string {
+	wellKnown.Do(func() {
+		wellKnown.services = loadServices()
+	})
+	return wellKnown.services[port]
+}
+
+// loadServices reads the TCP entries of the services file, falling back
+// to the built-in list if it can't be read
+func loadServices() map[int]string {
+	f, err := os.Open(servicesFile)
+	if err != nil {
+		slog.Debug("reading services file failed, using built-in names", "path", servicesFile, "error", err)
+		return fallbackServices
+	}
+	defer f.Close()
+
+	services := make(map[int]string)
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		line, _, _ := strings.Cut(lines.Text(), "#")
+		// e.g. "postgresql  5432/tcp  postgres"
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		portStr, proto, ok := strings.Cut(fields[1], "/")
+		if !ok || proto != "tcp" {
+			continue
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			continue
+		}
+		// The first entry for a port is its primary name
+		if _, seen := services[port]; !seen {
+			services[port] = fields[0]
+		}
+	}
+	if err := lines.Err(); err != nil || len(services) == 0 {
+		slog.Debug("services file unusable, using built-in names", "path", servicesFile, "error", err)
+		return fallbackServices
+	}
+	return services
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serviceCacheTTL is how long the PID to Windows service map is reused
+// before tasklist is run again
+const serviceCacheTTL = 30 * time.Second
+
+// serviceCache remembers which Windows services each PID hosts
+var serviceCache struct {
+	sync.Mutex
+	services map[int32]string
+	updated  time.Time
+}
+
+// getWindowsServices returns the Windows services hosted by pid, such as
+// "Dnscache, LanmanWorkstation" for a svchost.exe, or "" if there are
+// none or it isn't Windows
+func getWindowsServices(pid int32) string {
+	if runtime.GOOS != "windows" || pid == 0 {
+		return ""
+	}
+
+	serviceCache.Lock()
+	defer serviceCache.Unlock()
+
+	if serviceCache.services == nil || time.Since(serviceCache.updated) >= serviceCacheTTL {
+		services, err := listWindowsServices()
+		if err != nil {
+			slog.Debug("listing windows services failed", "error", err)
+		}
+		// Failures are cached too so tasklist isn't retried every scan
+		serviceCache.services = services
+		serviceCache.updated = time.Now()
+	}
+	return serviceCache.services[pid]
+}
+
+// listWindowsServices runs `tasklist /svc` and maps PIDs to the services
+// they host
+func listWindowsServices() (map[int32]string, error) {
+	out, err := runCommand(context.Background(), commandTimeout, "tasklist", "tasklist", "/svc", "/fo", "csv", "/nh")
+	if errors.Is(err, ErrCommandTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tasklist failed: %w", err)
+	}
+	return parseTasklistServices(string(out))
+}
+
+// parseTasklistServices parses `tasklist /svc /fo csv /nh` output, e.g.
+// "svchost.exe","1234","Dnscache,LanmanWorkstation". Processes hosting no
+// service are listed with "N/A" and left out.
+func parseTasklistServices(out string) (map[int32]string, error) {
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tasklist output: %w", err)
+	}
+
+	services := make(map[int32]string)
+	for _, r := range records {
+		if len(r) < 3 || r[2] == "N/A" {
+			continue
+		}
+		pid, err := strconv.ParseInt(r[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		services[int32(pid)] = strings.ReplaceAll(r[2], ",", ", ")
+	}
+	return services, nil
+}
@@ -0,0 +1,156 @@
+// Package server serves gaze's live port data as JSON over HTTP, for
+// viewing it from a browser or scripts while the UI runs.
+//
+// Endpoints, all GET:
+//
+//	/api/ports            a recent scan: {"timestamp": ..., "ports": [PortInfo...]}
+//	/api/history          tracked ports, most recently seen first: [PortHistory...]
+//	/api/events?limit=N   the N most recent events, oldest first: [PortEvent...]
+//
+// PortInfo fields keep their Go names, e.g. "Port" and "Process";
+// PortHistory and PortEvent use their snake_case JSON tags. Errors are
+// {"error": "..."} with a 4xx or 5xx status.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// defaultEventLimit is how many events /api/events returns without a limit
+const defaultEventLimit = 100
+
+// scanReuse is how long a scan answers /api/ports before the next request
+// scans again. Scans run one at a time, so a burst of requests costs one
+// scan rather than one each.
+const scanReuse = 2 * time.Second
+
+// readHeaderTimeout bounds how long a client may take to send its request
+// headers
+const readHeaderTimeout = 5 * time.Second
+
+// portsResponse is the body of /api/ports
+type portsResponse struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Ports     []scanner.PortInfo `json:"ports"`
+}
+
+// Start serves the API on addr in the background, scanning with cfg
+// without the ignored ports and reading history from tracker. It listens
+// before returning so a bad address is reported up front. An address
+// without a host, such as ":8090", is served on loopback only, since the
+// API exposes command lines and users without authentication.
+func Start(addr string, tracker *history.Tracker, cfg scanner.Config, ignored []scanner.PortRange) error {
+	ln, err := net.Listen("tcp", loopbackDefault(addr))
+	if err != nil {
+		return err
+	}
+	if !isLoopback(ln.Addr()) {
+		slog.Warn("serving the unauthenticated API beyond this machine", "addr", ln.Addr().String())
+	}
+	slog.Info("serving API", "addr", ln.Addr().String())
+
+	srv := &http.Server{
+		Handler:           newHandler(tracker, cfg, ignored),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			slog.Warn("API server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// loopbackDefault puts an address without a host on localhost
+func loopbackDefault(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
+
+// isLoopback reports whether a listener only accepts local connections
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// portScans runs the scans behind /api/ports one at a time, answering
+// requests within scanReuse of a scan with its result
+type portScans struct {
+	mu      sync.Mutex
+	cfg     scanner.Config
+	ignored []scanner.PortRange
+	last    portsResponse
+}
+
+// get returns a scan no older than scanReuse, scanning if needed
+func (s *portScans) get() (portsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.last.Timestamp) < scanReuse {
+		return s.last, nil
+	}
+	ports, err := scanner.ScanPorts(s.cfg)
+	if err != nil {
+		return portsResponse{}, err
+	}
+	s.last = portsResponse{Timestamp: time.Now(), Ports: scanner.WithoutPorts(ports, s.ignored)}
+	return s.last, nil
+}
+
+// newHandler routes the API endpoints
+func newHandler(tracker *history.Tracker, cfg scanner.Config, ignored []scanner.PortRange) http.Handler {
+	scans := &portScans{cfg: cfg, ignored: ignored}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/ports", func(w http.ResponseWriter, r *http.Request) {
+		resp, err := scans.get()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, resp)
+	})
+	mux.HandleFunc("GET /api/history", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, tracker.GetAllHistory())
+	})
+	mux.HandleFunc("GET /api/events", func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultEventLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be a positive number, not %q", s))
+				return
+			}
+			limit = n
+		}
+		writeJSON(w, tracker.GetRecentEvents(limit))
+	})
+	return mux
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("writing API response failed", "error", err)
+	}
+}
+
+// writeError writes err as a JSON error response with the given status
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
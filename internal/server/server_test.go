@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestLoopbackDefault(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{":8090", "localhost:8090"},
+		{"localhost:8090", "localhost:8090"},
+		{"0.0.0.0:8090", "0.0.0.0:8090"},
+		{"[::1]:8090", "[::1]:8090"},
+		{"bad", "bad"},
+	}
+
+	for _, tt := range tests {
+		if got := loopbackDefault(tt.addr); got != tt.want {
+			t.Errorf("loopbackDefault(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr net.Addr
+		want bool
+	}{
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8090}, true},
+		{&net.TCPAddr{IP: net.IPv6loopback, Port: 8090}, true},
+		{&net.TCPAddr{IP: net.IPv4zero, Port: 8090}, false},
+		{&net.TCPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 8090}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopback(tt.addr); got != tt.want {
+			t.Errorf("isLoopback(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestPortsReusesRecentScan(t *testing.T) {
+	cfg := scanner.DefaultConfig()
+	cfg.NoHTTPCheck = true
+	tracker := history.NewTracker(history.DefaultMaxEvents, history.DefaultMaxHistories, history.DefaultMaxPortEvents, history.DefaultMaxSamples)
+	handler := newHandler(tracker, cfg, nil)
+
+	get := func() portsResponse {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/ports", nil))
+		if rec.Code != http.StatusOK {
+			t.Skipf("scan failed here: %s", rec.Body)
+		}
+		var resp portsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	first, second := get(), get()
+	if !first.Timestamp.Equal(second.Timestamp) {
+		t.Errorf("second request scanned again at %v, want the scan from %v reused", second.Timestamp, first.Timestamp)
+	}
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/junjiang/gaze/internal/history"
+)
+
+// ageBarWidth is the width in cells of the Age column's bar
+const ageBarWidth = 10
+
+// barEighths draws the fractional end of a bar in eighths of a cell
+var barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// ageBar draws uptime as a bar scaled against the longest uptime listed
+func ageBar(uptime, longest time.Duration) string {
+	return bar(float64(uptime), float64(longest), ageBarWidth)
+}
+
+// bar draws value as a bar of up to width cells, scaled against largest.
+// Bars are plain block characters, as the table can't measure styled cells.
+func bar(value, largest float64, width int) string {
+	if value <= 0 || largest <= 0 {
+		return ""
+	}
+	eighths := int(value / largest * float64(width) * 8)
+	eighths = min(max(eighths, 1), width*8)
+	return strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+}
+
+// longestUptime returns the greatest uptime among the listed ports
+func (m Model) longestUptime() time.Duration {
+	var longest time.Duration
+	for _, p := range m.ports {
+		longest = max(longest, m.historyTracker.GetUptime(history.KeyOf(p)))
+	}
+	return longest
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// alertScans is how many scans a newly alerted port stays highlighted
+const alertScans = 3
+
+// alertMarker flags ports that opened after startup in --alert-new mode
+const alertMarker = "!! "
+
+var alertStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FAFAFA")).
+	Background(lipgloss.Color("#C800C8")).
+	Bold(true)
+
+// newPortAlerter raises alerts for ports that weren't open when gaze
+// started. The ports of the first scan form the allowlist; each other
+// port is alerted the first time it opens, from the history tracker's
+// events.
+type newPortAlerter struct {
+	log     io.Writer                // Alert log, if any
+	known   map[history.PortKey]bool // Allowlisted or already alerted
+	pending []history.PortEvent
+	active  map[history.PortKey]int // Port -> scans left to highlight
+	count   int
+}
+
+// newNewPortAlerter alerts on ports opening as tracked by tracker, also
+// writing them to log if it isn't nil
+func newNewPortAlerter(tracker *history.Tracker, log io.Writer) *newPortAlerter {
+	a := &newPortAlerter{
+		log:    log,
+		known:  make(map[history.PortKey]bool),
+		active: make(map[history.PortKey]int),
+	}
+	tracker.Subscribe(func(e history.PortEvent) {
+		if e.EventType == history.EventPortOpened {
+			a.pending = append(a.pending, e)
+		}
+	})
+	return a
+}
+
+// isAlerted reports whether a port is highlighted as newly opened
+func (a *newPortAlerter) isAlerted(p scanner.PortInfo) bool {
+	return a != nil && a.active[history.KeyOf(p)] > 0
+}
+
+// runNewPortAlerts alerts on the ports that opened in the last scan for
+// the first time, and ages the highlights of earlier ones. The first scan
+// only sets up the allowlist, even when ports are staged by
+// --stable-scans, so ports open at startup stay quiet.
+func (m *Model) runNewPortAlerts(firstScan bool) {
+	a := m.alerter
+	if a == nil {
+		return
+	}
+	pending := a.pending
+	a.pending = nil
+	for key, left := range a.active {
+		if left <= 1 {
+			delete(a.active, key)
+		} else {
+			a.active[key] = left - 1
+		}
+	}
+	if firstScan {
+		for _, p := range m.allPorts {
+			a.known[history.KeyOf(p)] = true
+		}
+		return
+	}
+
+	for _, e := range pending {
+		key := e.Key()
+		if a.known[key] {
+			continue
+		}
+		a.known[key] = true
+		a.active[key] = alertScans
+		a.count++
+		slog.Warn("new port opened", "port", e.Port, "protocol", e.Protocol, "pid", e.PID, "process", e.Process)
+		m.setStatus(fmt.Sprintf("New port %s opened by %s (PID %d)", key, e.Process, e.PID))
+		if a.log == nil {
+			continue
+		}
+		_, err := fmt.Fprintf(a.log, "%s\tport=%s\tpid=%d\tprocess=%s\n", e.Timestamp.Format(time.RFC3339), key, e.PID, e.Process)
+		if err != nil {
+			m.err = fmt.Errorf("failed to write alert log: %w", err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/export"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// AutoExportOptions configures periodic snapshot exports
+type AutoExportOptions struct {
+	Interval time.Duration // Zero disables auto-export
+	Dir      string
+	Keep     int // Number of exports of each format to retain
+}
+
+type autoExportTickMsg struct{}
+type autoExportDoneMsg struct {
+	at  time.Time
+	err error
+}
+
+// autoExportTick schedules the next automatic export
+func autoExportTick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return autoExportTickMsg{}
+	})
+}
+
+// autoExport writes a JSON and CSV snapshot of ports and prunes old ones
+func autoExport(ports []scanner.PortInfo, opts AutoExportOptions) tea.Cmd {
+	return func() tea.Msg {
+		if err := export.AutoExport(ports, opts.Dir, opts.Keep); err != nil {
+			return autoExportDoneMsg{err: fmt.Errorf("auto-export failed: %w", err)}
+		}
+		return autoExportDoneMsg{at: time.Now()}
+	}
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// AutoKillRule selects ports whose processes are killed as soon as they
+// open. A port must match both the port list and the process pattern,
+// whichever are set.
+type AutoKillRule struct {
+	Ports   []scanner.PortRange
+	Process *regexp.Regexp
+}
+
+// ParseAutoKillRule parses an --auto-kill pattern: ports, a process name
+// regexp after a colon, or both, e.g. "80", "3000-3999:node" or
+// ":^python"
+func ParseAutoKillRule(spec string) (AutoKillRule, error) {
+	var rule AutoKillRule
+	ports, pattern, hasPattern := strings.Cut(spec, ":")
+
+	var err error
+	if rule.Ports, err = scanner.ParsePortList(ports); err != nil {
+		return AutoKillRule{}, err
+	}
+	if hasPattern && pattern != "" {
+		if rule.Process, err = regexp.Compile(pattern); err != nil {
+			return AutoKillRule{}, fmt.Errorf("invalid process pattern: %w", err)
+		}
+	}
+	if len(rule.Ports) == 0 && rule.Process == nil {
+		return AutoKillRule{}, fmt.Errorf("pattern %q matches no ports or processes", spec)
+	}
+	return rule, nil
+}
+
+// Matches reports whether a port held by process falls under the rule
+func (r AutoKillRule) Matches(port int, process string) bool {
+	if len(r.Ports) > 0 {
+		inRange := false
+		for _, pr := range r.Ports {
+			inRange = inRange || pr.Contains(port)
+		}
+		if !inRange {
+			return false
+		}
+	}
+	return r.Process == nil || r.Process.MatchString(process)
+}
+
+// autoKiller collects the opened ports matching the rule as the history
+// tracker records them, for the model to kill after each scan
+type autoKiller struct {
+	rule    AutoKillRule
+	known   map[history.PortKey]bool // Open at startup and not closed since
+	pending []history.PortEvent
+	killed  int
+}
+
+// watch subscribes to the tracker's events
+func (a *autoKiller) watch(tracker *history.Tracker) {
+	tracker.Subscribe(func(e history.PortEvent) {
+		switch {
+		case e.EventType == history.EventPortClosed:
+			delete(a.known, e.Key())
+		case e.EventType == history.EventPortOpened && e.PID != 0 && a.rule.Matches(e.Port, e.Process):
+			a.pending = append(a.pending, e)
+		}
+	})
+}
+
+// due returns the matching ports opened since the last call, given the
+// ports of the scan that just finished. The first scan's ports are
+// remembered rather than killed, even when --stable-scans only reports
+// them as opened a few scans later, so ports open at startup are left
+// alone until they close.
+func (a *autoKiller) due(firstScan bool, ports []scanner.PortInfo) []history.PortEvent {
+	pending := a.pending
+	a.pending = nil
+	if firstScan {
+		a.known = make(map[history.PortKey]bool, len(ports))
+		for _, p := range ports {
+			a.known[history.KeyOf(p)] = true
+		}
+		return nil
+	}
+
+	var due []history.PortEvent
+	for _, e := range pending {
+		if !a.known[e.Key()] {
+			due = append(due, e)
+		}
+	}
+	return due
+}
+
+// runAutoKills kills the processes of matching ports opened in the last
+// scan. Ports already open when gaze started are left alone.
+func (m *Model) runAutoKills(firstScan bool) {
+	if m.autoKill == nil {
+		return
+	}
+
+	for _, e := range m.autoKill.due(firstScan, m.allPorts) {
+		action := fmt.Sprintf("auto-kill PID %d (%s), which opened :%d", e.PID, e.Process, e.Port)
+		if !m.actionAllowed(action) {
+			slog.Warn("auto-kill not performed", "action", action, "dry_run", m.dryRun, "read_only", m.readOnly)
+			continue
+		}
+		if err := scanner.KillProcess(e.PID); err != nil && !scanner.IsProcessGone(err) {
+			slog.Error("auto-kill failed", "port", e.Port, "pid", e.PID, "process", e.Process, "error", err)
+			m.err = fmt.Errorf("auto-kill of PID %d on :%d failed: %w", e.PID, e.Port, killFailure(err))
+			continue
+		}
+		slog.Warn("auto-killed process", "port", e.Port, "pid", e.PID, "process", e.Process)
+		m.autoKill.killed++
+		m.setStatus(fmt.Sprintf("Auto-killed PID %d (%s), which opened :%d", e.PID, e.Process, e.Port))
+	}
+}
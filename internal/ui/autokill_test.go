@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// listening returns scanned TCP ports held by node
+func listening(ports ...int) []scanner.PortInfo {
+	infos := make([]scanner.PortInfo, 0, len(ports))
+	for _, port := range ports {
+		infos = append(infos, scanner.PortInfo{Port: port, PID: int32(port), Process: "node", SocketType: "tcp"})
+	}
+	return infos
+}
+
+func TestAutoKillerDue(t *testing.T) {
+	tests := []struct {
+		name   string
+		stable int
+		scans  [][]int
+		want   []int // Ports due for killing, across all scans
+	}{
+		{
+			name:   "startup ports left alone",
+			stable: 1,
+			scans:  [][]int{{3000}, {3000}, {3000}},
+			want:   nil,
+		},
+		{
+			name:   "new port killed",
+			stable: 1,
+			scans:  [][]int{{3000}, {3000, 3001}},
+			want:   []int{3001},
+		},
+		{
+			name:   "startup ports left alone with stable scans",
+			stable: 2,
+			scans:  [][]int{{3000}, {3000}, {3000}},
+			want:   nil,
+		},
+		{
+			name:   "new port killed once stable",
+			stable: 2,
+			scans:  [][]int{{3000}, {3000, 3001}, {3000, 3001}},
+			want:   []int{3001},
+		},
+		{
+			name:   "startup port killed after reopening",
+			stable: 2,
+			scans:  [][]int{{3000}, {3000}, {}, {3000}, {3000}},
+			want:   []int{3000},
+		},
+		{
+			name:   "unmatched port left alone",
+			stable: 1,
+			scans:  [][]int{{}, {8080}},
+			want:   nil,
+		},
+	}
+
+	rule, err := ParseAutoKillRule("3000-3999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := history.NewTracker(history.DefaultMaxEvents, history.DefaultMaxHistories, history.DefaultMaxPortEvents, history.DefaultMaxSamples)
+			tracker.SetStableThreshold(tt.stable)
+			killer := &autoKiller{rule: rule}
+			killer.watch(tracker)
+
+			var got []int
+			for i, scan := range tt.scans {
+				ports := listening(scan...)
+				tracker.Update(ports)
+				for _, e := range killer.due(i == 0, ports) {
+					got = append(got, e.Port)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("due ports = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// openInBrowser opens a web port's URL in the default browser
+func (m *Model) openInBrowser(p scanner.PortInfo) {
+	url, ok := m.scanConfig.WebURL(p)
+	if !ok {
+		m.err = fmt.Errorf("port %d isn't a web port; add it to --http-ports to open it", p.Port)
+		return
+	}
+	if err := openURL(url); err != nil {
+		m.err = fmt.Errorf("failed to open %s: %w", url, err)
+		return
+	}
+	m.setStatus("Opening " + url)
+}
+
+// openURL hands url to the platform's opener without waiting for the
+// browser, which may keep running long after gaze exits
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		// start is a cmd builtin; the empty argument is the window title
+		cmd = exec.Command("cmd", "/c", "start", "", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	slog.Info("opened in browser", "url", url)
+	go cmd.Wait()
+	return nil
+}
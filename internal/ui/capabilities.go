@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// capabilityEnabled reports whether the named capability was found working
+func (m Model) capabilityEnabled(name string) bool {
+	for _, c := range m.capabilities {
+		if c.Name == name {
+			return c.Enabled
+		}
+	}
+	return false
+}
+
+// notePermissionDenied lists missing privileges among the capabilities
+// while scans find processes gaze isn't allowed to read. The first time,
+// the status line says so.
+func (m *Model) notePermissionDenied(denied int) {
+	m.capabilities = slices.DeleteFunc(slices.Clone(m.capabilities), isPrivileges)
+	if denied == 0 {
+		return
+	}
+	m.capabilities = append(m.capabilities, scanner.PrivilegeCapability(denied))
+	if !m.deniedNoted {
+		m.deniedNoted = true
+		m.setStatus(m.capabilityBanner())
+	}
+}
+
+// isPrivileges reports whether c is the missing privileges entry
+func isPrivileges(c scanner.Capability) bool {
+	return c.Name == "privileges"
+}
+
+// capabilityBanner summarizes degraded capabilities for the status line
+// at startup, or returns "" when everything is available
+func (m Model) capabilityBanner() string {
+	degraded := scanner.Degraded(m.capabilities)
+	if len(degraded) == 0 {
+		return ""
+	}
+	names := make([]string, len(degraded))
+	for i, c := range degraded {
+		names[i] = c.Name
+	}
+	return "Unavailable: " + strings.Join(names, ", ") + " • press " + m.keys.Capabilities.Help().Key + " for details"
+}
+
+// renderCapabilities lists what works in this environment and why the
+// rest doesn't
+func (m Model) renderCapabilities() string {
+	lines := []string{"Capabilities:"}
+	for _, c := range m.capabilities {
+		mark := "✓"
+		if !c.Enabled {
+			mark = "✗"
+		}
+		lines = append(lines, mark+" "+c.Name+": "+c.Detail)
+	}
+	return explainStyle.Render(strings.Join(lines, "\n")) + "\n"
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestNotePermissionDenied(t *testing.T) {
+	tests := []struct {
+		name       string
+		scans      []int // Denied sockets in each scan
+		wantListed bool
+		wantStatus bool
+	}{
+		{"never denied", []int{0, 0}, false, false},
+		{"denied", []int{0, 3}, true, true},
+		{"no longer denied", []int{3, 0}, false, true},
+		{"denied again", []int{3, 0, 2}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{keys: DefaultKeyMap(), capabilities: []scanner.Capability{{Name: "docker", Enabled: true}}}
+			for _, denied := range tt.scans {
+				m.notePermissionDenied(denied)
+			}
+
+			listed := 0
+			for _, c := range m.capabilities {
+				if isPrivileges(c) {
+					listed++
+				}
+			}
+			if listed > 1 || (listed == 1) != tt.wantListed {
+				t.Errorf("privileges listed %d times, want listed %v", listed, tt.wantListed)
+			}
+			if got := m.statusMsg != ""; got != tt.wantStatus {
+				t.Errorf("status %q, want set %v", m.statusMsg, tt.wantStatus)
+			}
+			if !m.capabilityEnabled("docker") {
+				t.Error("probed capabilities lost")
+			}
+		})
+	}
+}
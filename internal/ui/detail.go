@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// detailStyle frames the detail panel. It is wider than the explanation
+// so long command lines wrap less.
+var detailStyle = explainStyle.Width(100)
+
+// Detail panel layout: labels are padded to line up the values, which
+// wrap within the rest of the panel's 98 columns inside its padding
+const (
+	detailLabelWidth = 12
+	detailValueWidth = 86
+)
+
+// detailValueStyle wraps long values, such as command lines, in place
+var detailValueStyle = lipgloss.NewStyle().Width(detailValueWidth)
+
+// detailInfoMsg carries the process details looked up for the detail view
+type detailInfoMsg struct {
+	pid  int32
+	info scanner.ProcessInfo
+	err  error
+}
+
+// openDetail shows the detail view for a port and starts looking up its
+// process's details
+func (m *Model) openDetail(p scanner.PortInfo) tea.Cmd {
+	m.detail = p
+	m.detailClosed = false
+	m.detailInfo = scanner.ProcessInfo{OpenFiles: -1}
+	m.detailInfoErr = nil
+	m.viewMode = ViewDetail
+	return loadDetailInfo(p.PID)
+}
+
+// loadDetailInfo looks up a process's details in the background, since
+// some platforms shell out for them
+func loadDetailInfo(pid int32) tea.Cmd {
+	if pid == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		info, err := scanner.GetProcessInfo(pid)
+		return detailInfoMsg{pid: pid, info: info, err: err}
+	}
+}
+
+// handleDetailInfo shows looked up process details, unless the view has
+// moved on to another process since
+func (m *Model) handleDetailInfo(msg detailInfoMsg) {
+	if m.viewMode != ViewDetail || msg.pid != m.detail.PID {
+		return
+	}
+	if msg.err != nil {
+		m.detailInfoErr = msg.err
+		return
+	}
+	m.detailInfo = msg.info
+	m.detailInfoErr = nil
+}
+
+// closeDetail returns from the detail view to the ports table
+func (m *Model) closeDetail() {
+	m.viewMode = ViewPorts
+	m.updateTableRows()
+	m.resizeTable()
+}
+
+// handleDetailKey handles keys in the detail view, which only closes or
+// quits
+func (m *Model) handleDetailKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.Type == tea.KeyEsc, key.Matches(msg, m.keys.Detail):
+		m.closeDetail()
+	case key.Matches(msg, m.keys.Quit):
+		return tea.Quit
+	}
+	return nil
+}
+
+// refreshDetail updates the detail view from the latest scan, looking up
+// the process's details again since its open files change. A port that
+// closed keeps its last known details.
+func (m *Model) refreshDetail() tea.Cmd {
+	want := selectionKeyOf(m.detail)
+	for _, p := range m.allPorts {
+		if selectionKeyOf(p) == want && p.PID == m.detail.PID {
+			m.detail = p
+			m.detailClosed = false
+			return loadDetailInfo(p.PID)
+		}
+	}
+	m.detailClosed = true
+	return nil
+}
+
+// renderDetail renders the detail panel as label/value lines
+func (m Model) renderDetail() string {
+	p, info := m.detail, m.detailInfo
+	rows := [][2]string{
+		{"Socket", socketTarget(p) + " " + p.SocketType},
+		{"Process", p.Process},
+		{"PID", fmt.Sprintf("%d", p.PID)},
+		{"Parent PID", detailCount(int(info.PPID), 0)},
+		{"User", p.User},
+		{"Command", p.Cmdline},
+		{"Exe", info.Exe},
+		{"Cwd", info.Cwd},
+		{"Open files", detailCount(info.OpenFiles, -1)},
+		{"CPU", m.detailCPU()},
+		{"Memory", fmt.Sprintf("%.1f MB", p.MemoryMB)},
+		{"Nice", detailNice(p)},
+		{"Uptime", history.FormatUptime(m.historyTracker.GetUptime(history.KeyOf(p)))},
+		{"Container", detailContainer(p)},
+		{"Proxies to", strings.Join(p.Upstreams, ", ")},
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		value := row[1]
+		if value == "" {
+			value = "-"
+		}
+		label := pidStyle.Render(fmt.Sprintf("%-*s", detailLabelWidth, row[0]))
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, label, detailValueStyle.Render(value)))
+	}
+	if m.detailInfoErr != nil {
+		lines = append(lines, "", warningStyle.Render("Some details are unavailable: "+m.detailInfoErr.Error()))
+	}
+	if m.detailClosed {
+		lines = append(lines, "", warningStyle.Render("The port has closed; these are its last known details."))
+	}
+	return detailStyle.Render(strings.Join(lines, "\n")) + "\n"
+}
+
+// detailCPU shows the process's CPU usage, followed by a sparkline of
+// the port's recent samples
+func (m Model) detailCPU() string {
+	cpu := fmt.Sprintf("%.1f%%", m.detail.CPUPercent)
+	samples := m.historyTracker.GetSamples(history.KeyOf(m.detail))
+	if len(samples) < 2 {
+		return cpu
+	}
+	span := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+	return fmt.Sprintf("%-8s%s  last %s", cpu, cpuSparkline(samples), history.FormatUptime(span))
+}
+
+// detailCount formats a number that is unknown when it equals unknown,
+// as details of other users' processes often are without root
+func detailCount(n, unknown int) string {
+	if n == unknown {
+		return "-"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// detailNice shows the process's nice value where it can be changed
+func detailNice(p scanner.PortInfo) string {
+	if p.PID == 0 || !scanner.CanRenice() {
+		return "-"
+	}
+	return fmt.Sprintf("%d", p.Niceness)
+}
+
+// detailContainer describes the container publishing a port, if any
+func detailContainer(p scanner.PortInfo) string {
+	if !p.IsContainer {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", p.ContainerName, p.ContainerImage)
+}
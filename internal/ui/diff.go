@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// diffHighlightScans is how many scans a newly opened or closed port stays highlighted
+const diffHighlightScans = 2
+
+const (
+	diffAddedMarker   = "+ "
+	diffRemovedMarker = "- "
+	newPortMarker     = "* " // First seen within the highlight window
+	unexpectedMarker  = "! " // Not allowed by the --baseline file
+)
+
+var (
+	diffAddedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#00FF00")).
+			Bold(true)
+
+	diffRemovedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5555")).
+				Strikethrough(true)
+
+	unexpectedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(lipgloss.Color("#CC0000")).
+			Bold(true)
+
+	newPortStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#FFFF00")).
+			Bold(true)
+)
+
+// scanDiff tracks ports that changed between consecutive scans
+type scanDiff struct {
+	initialized bool
+	previous    map[history.PortKey]scanner.PortInfo
+	added       map[history.PortKey]int              // Port -> scans left to highlight
+	removed     map[history.PortKey]int              // Port -> scans left to show
+	removedInfo map[history.PortKey]scanner.PortInfo // Last known info for removed ports
+}
+
+func newScanDiff() *scanDiff {
+	return &scanDiff{
+		previous:    make(map[history.PortKey]scanner.PortInfo),
+		added:       make(map[history.PortKey]int),
+		removed:     make(map[history.PortKey]int),
+		removedInfo: make(map[history.PortKey]scanner.PortInfo),
+	}
+}
+
+// Apply compares a new scan against the previous one and updates the
+// transient highlights
+func (d *scanDiff) Apply(ports []scanner.PortInfo) {
+	// Age existing highlights
+	for port, left := range d.added {
+		if left <= 1 {
+			delete(d.added, port)
+		} else {
+			d.added[port] = left - 1
+		}
+	}
+	for port, left := range d.removed {
+		if left <= 1 {
+			delete(d.removed, port)
+			delete(d.removedInfo, port)
+		} else {
+			d.removed[port] = left - 1
+		}
+	}
+
+	current := make(map[history.PortKey]scanner.PortInfo, len(ports))
+	for _, p := range ports {
+		if p.SocketType != scanner.SocketUnix {
+			current[history.KeyOf(p)] = p
+		}
+	}
+
+	// The first scan establishes the baseline, nothing is "new" yet
+	if d.initialized {
+		for port := range current {
+			if _, existed := d.previous[port]; !existed {
+				d.added[port] = diffHighlightScans
+				delete(d.removed, port)
+				delete(d.removedInfo, port)
+			}
+		}
+		for port, info := range d.previous {
+			if _, exists := current[port]; !exists {
+				d.removed[port] = diffHighlightScans
+				d.removedInfo[port] = info
+				delete(d.added, port)
+			}
+		}
+	}
+
+	d.previous = current
+	d.initialized = true
+}
+
+// Forget stops tracking a port number over any protocol so its
+// disappearance isn't highlighted
+func (d *scanDiff) Forget(port int) {
+	for key := range d.previous {
+		if key.Port == port {
+			delete(d.previous, key)
+		}
+	}
+	for key := range d.added {
+		if key.Port == port {
+			delete(d.added, key)
+		}
+	}
+	for key := range d.removed {
+		if key.Port == port {
+			delete(d.removed, key)
+			delete(d.removedInfo, key)
+		}
+	}
+}
+
+// IsAdded reports whether a port appeared in one of the recent scans
+func (d *scanDiff) IsAdded(p scanner.PortInfo) bool {
+	_, ok := d.added[history.KeyOf(p)]
+	return ok
+}
+
+// Removed returns the ports that recently disappeared
+func (d *scanDiff) Removed() []scanner.PortInfo {
+	removed := make([]scanner.PortInfo, 0, len(d.removedInfo))
+	for _, info := range d.removedInfo {
+		removed = append(removed, info)
+	}
+	return removed
+}
+
+// colorizeDiffRows styles rendered table rows that carry a diff marker.
+// Styling is applied after the table renders because the table measures
+// cell widths without accounting for ANSI escape sequences. The selected
+// row already carries its own styling and is left untouched. Unmarked
+// rows get their HTTP status, found at http, and process state colored.
+func colorizeDiffRows(view string, http columnSpan) string {
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "\x1b") {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case strings.HasPrefix(trimmed, alertMarker):
+			lines[i] = alertStyle.Render(line)
+		case strings.HasPrefix(trimmed, unexpectedMarker):
+			lines[i] = unexpectedStyle.Render(line)
+		case strings.HasPrefix(trimmed, newPortMarker):
+			lines[i] = newPortStyle.Render(line)
+		case strings.HasPrefix(trimmed, diffAddedMarker):
+			lines[i] = diffAddedStyle.Render(line)
+		case strings.HasPrefix(trimmed, diffRemovedMarker):
+			lines[i] = diffRemovedStyle.Render(line)
+		default:
+			lines[i] = colorizeProcState(colorizeHTTPStatus(line, http))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/junjiang/gaze/internal/history"
+)
+
+// eventFeedSize is how many recent events are listed under the history table
+const eventFeedSize = 5
+
+// EventFilter selects which events the history view's event feed shows
+type EventFilter int
+
+const (
+	EventFilterAll EventFilter = iota
+	EventFilterOpened
+	EventFilterClosed
+	EventFilterFlapping
+	EventFilterWatched
+	eventFilterCount
+)
+
+// String returns the display name of the filter
+func (f EventFilter) String() string {
+	switch f {
+	case EventFilterOpened:
+		return "Opened"
+	case EventFilterClosed:
+		return "Closed"
+	case EventFilterFlapping:
+		return "Flapping"
+	case EventFilterWatched:
+		return "Watched"
+	}
+	return "All"
+}
+
+// matches reports whether an event passes the filter. The watched filter
+// passes nothing without a watcher.
+func (f EventFilter) matches(e history.PortEvent, tracker *history.Tracker, watcher *portWatcher) bool {
+	switch f {
+	case EventFilterOpened:
+		return e.EventType == history.EventPortOpened
+	case EventFilterClosed:
+		return e.EventType == history.EventPortClosed
+	case EventFilterFlapping:
+		return tracker.IsFlapping(e.Key())
+	case EventFilterWatched:
+		return watcher != nil && watcher.watches(e.Port)
+	}
+	return true
+}
+
+// filteredEvents returns the most recent events matching the filter, newest first
+func (m Model) filteredEvents(limit int) []history.PortEvent {
+	all := m.historyTracker.GetRecentEvents(0)
+	events := make([]history.PortEvent, 0, limit)
+	for i := len(all) - 1; i >= 0 && len(events) < limit; i-- {
+		if m.eventFilter.matches(all[i], m.historyTracker, m.watcher) {
+			events = append(events, all[i])
+		}
+	}
+	return events
+}
+
+// nextEventFilter returns the filter after f in the cycle, skipping the
+// watched filter when no ports are watched
+func (m Model) nextEventFilter(f EventFilter) EventFilter {
+	f = (f + 1) % eventFilterCount
+	if f == EventFilterWatched && m.watcher == nil {
+		f = (f + 1) % eventFilterCount
+	}
+	return f
+}
+
+// renderEventFeed renders up to limit recent events matching the active filter
+func (m Model) renderEventFeed(limit int) string {
+	s := pidStyle.Render(fmt.Sprintf("Recent events (%s):", m.eventFilter)) + "\n"
+
+	events := m.filteredEvents(limit)
+	if len(events) == 0 {
+		return s + pidStyle.Render("  no matching events") + "\n"
+	}
+
+	for _, e := range events {
+		style := eventOpenStyle
+		if e.EventType == history.EventPortClosed {
+			style = eventCloseStyle
+		}
+		s += fmt.Sprintf("  %s %s port %s (%s, PID %d)\n",
+			pidStyle.Render(e.Timestamp.Format("15:04:05")),
+			style.Render(fmt.Sprintf("%-6s", e.EventType)),
+			e.Key(),
+			e.Process,
+			e.PID)
+	}
+	return s
+}
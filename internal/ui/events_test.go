@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestNextEventFilter(t *testing.T) {
+	watcher := newPortWatcher([]scanner.PortRange{{Start: 4000, End: 4000}},
+		history.NewTracker(history.DefaultMaxEvents, history.DefaultMaxHistories, history.DefaultMaxPortEvents, history.DefaultMaxSamples))
+
+	tests := []struct {
+		name    string
+		watcher *portWatcher
+		from    EventFilter
+		want    EventFilter
+	}{
+		{"to watched", watcher, EventFilterFlapping, EventFilterWatched},
+		{"wraps from watched", watcher, EventFilterWatched, EventFilterAll},
+		{"skips watched without a watch list", nil, EventFilterFlapping, EventFilterAll},
+		{"opened to closed", nil, EventFilterOpened, EventFilterClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{watcher: tt.watcher}
+			if got := m.nextEventFilter(tt.from); got != tt.want {
+				t.Errorf("nextEventFilter(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventFilterWatched(t *testing.T) {
+	tracker := history.NewTracker(history.DefaultMaxEvents, history.DefaultMaxHistories, history.DefaultMaxPortEvents, history.DefaultMaxSamples)
+	watcher := newPortWatcher([]scanner.PortRange{{Start: 4000, End: 4010}}, tracker)
+
+	tests := []struct {
+		name    string
+		watcher *portWatcher
+		port    int
+		want    bool
+	}{
+		{"watched port", watcher, 4005, true},
+		{"unwatched port", watcher, 3000, false},
+		{"no watch list", nil, 4005, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := history.PortEvent{Port: tt.port, Protocol: scanner.SocketTCP, EventType: history.EventPortOpened}
+			if got := EventFilterWatched.matches(e, tracker, tt.watcher); got != tt.want {
+				t.Errorf("matches(port %d) = %v, want %v", tt.port, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+var explainStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#7D56F4")).
+	Padding(0, 1).
+	Width(80)
+
+// explainPort describes in plain words what is holding a port and what
+// can be done about it, naming killKey as the key that kills it
+func explainPort(p scanner.PortInfo, killKey string) string {
+	if p.PID == 0 {
+		return fmt.Sprintf("%s is in use, but the process holding it isn't visible to gaze. "+
+			"Run gaze with elevated privileges to see who owns it.", socketLabel(p))
+	}
+
+	var details []string
+	details = append(details, fmt.Sprintf("PID %d", p.PID))
+	if !p.StartTime.IsZero() {
+		details = append(details, "started "+history.FormatUptime(time.Since(p.StartTime))+" ago")
+	}
+	if p.MemoryMB > 0 {
+		details = append(details, fmt.Sprintf("%.0fMB", p.MemoryMB))
+	}
+	if p.IsContainer {
+		details = append(details, "in container "+p.ContainerName)
+	}
+	if p.ServiceName != "" {
+		details = append(details, "hosting the "+p.ServiceName+" service")
+	}
+
+	s := fmt.Sprintf("%s is held by %s (%s)", socketLabel(p), p.Process, strings.Join(details, ", "))
+	if p.HTTPStatus > 0 {
+		s += fmt.Sprintf(", responding %d on HTTP", p.HTTPStatus)
+		if p.DetectedServer != "" {
+			s += " as " + p.DetectedServer
+		}
+	}
+	s += "."
+	if p.WellKnown != "" {
+		s += fmt.Sprintf(" Port %d is registered for %s.", p.Port, p.WellKnown)
+	}
+
+	if p.IsContainer {
+		s += fmt.Sprintf(" To reuse the port, stop the %s container.", p.ContainerName)
+	} else {
+		s += " To reuse the port, stop the process or kill it with " + killKey + "."
+	}
+	return s
+}
+
+// renderExplain renders the explanation for the highlighted port
+func (m Model) renderExplain() string {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.ports) {
+		return ""
+	}
+	return explainStyle.Render(explainPort(m.ports[cursor], m.keys.Kill.Help().Key)) + "\n"
+}
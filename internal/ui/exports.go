@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxExportRecords is how many exports are remembered in a session
+const maxExportRecords = 10
+
+// exportRecord is an export made during this session
+type exportRecord struct {
+	paths []string
+	at    time.Time
+}
+
+// recordExport remembers an export, dropping the oldest beyond the limit
+func (m *Model) recordExport(paths []string) {
+	m.exports = append(m.exports, exportRecord{paths: paths, at: time.Now()})
+	if len(m.exports) > maxExportRecords {
+		m.exports = m.exports[len(m.exports)-maxExportRecords:]
+	}
+}
+
+// renderExports lists this session's exports, newest first
+func (m Model) renderExports() string {
+	if len(m.exports) == 0 {
+		return explainStyle.Render("No exports yet this session. Press e to export.") + "\n"
+	}
+
+	lines := []string{"Exports this session:"}
+	for i := len(m.exports) - 1; i >= 0; i-- {
+		e := m.exports[i]
+		lines = append(lines, fmt.Sprintf("%s  %s", e.at.Format("15:04:05"), strings.Join(e.paths, ", ")))
+	}
+	return explainStyle.Render(strings.Join(lines, "\n")) + "\n"
+}
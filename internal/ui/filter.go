@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// showsPort reports whether a port passes the active table filters
+func (m Model) showsPort(p scanner.PortInfo) bool {
+	if m.containersOnly && !p.IsContainer {
+		return false
+	}
+	if m.pidFilter != 0 && p.PID != m.pidFilter {
+		return false
+	}
+	if len(m.portRanges) > 0 && (p.SocketType == scanner.SocketUnix || !m.showsPortNumber(p.Port)) {
+		return false
+	}
+	if m.filter != "" && !matchesFilter(p, m.filter) {
+		return false
+	}
+	return true
+}
+
+// showsPortNumber reports whether a port number is listed by --range,
+// which lists every port when not given
+func (m Model) showsPortNumber(port int) bool {
+	if len(m.portRanges) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(m.portRanges, func(r scanner.PortRange) bool { return r.Contains(port) })
+}
+
+// matchesFilter reports whether the port's process name, port number,
+// PID or user contains the query, ignoring case
+func matchesFilter(p scanner.PortInfo, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(p.Process), query) ||
+		strings.Contains(strings.ToLower(p.Endpoint()), query) ||
+		strings.Contains(fmt.Sprintf("%d", p.PID), query) ||
+		strings.Contains(strings.ToLower(p.User), query)
+}
+
+// setFilter changes the search query and re-filters the table
+func (m *Model) setFilter(query string) {
+	m.filter = query
+	m.applyFilters()
+	m.updateTableRows()
+}
+
+// applyFilters rebuilds the listed ports from the last scan, keeping those
+// that pass the active filters
+func (m *Model) applyFilters() {
+	ports := make([]scanner.PortInfo, 0, len(m.allPorts))
+	for _, p := range m.allPorts {
+		if m.showsPort(p) {
+			ports = append(ports, p)
+		}
+	}
+	m.ports = ports
+	m.sortPorts()
+}
+
+// filterStatus describes the active filters for the status line
+func (m Model) filterStatus() string {
+	var filters []string
+	if m.containersOnly {
+		filters = append(filters, "Containers only")
+	}
+	if m.pidFilter != 0 {
+		filters = append(filters, fmt.Sprintf("PID %d", m.pidFilter))
+	}
+	if len(m.portRanges) > 0 {
+		ranges := make([]string, len(m.portRanges))
+		for i, r := range m.portRanges {
+			ranges[i] = r.String()
+		}
+		filters = append(filters, "Ports "+strings.Join(ranges, ","))
+	}
+	if m.filter != "" {
+		filters = append(filters, fmt.Sprintf("%q", m.filter))
+	}
+	return strings.Join(filters, ", ")
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// httpRedirectStyle marks 3xx responses, between httpOKStyle and
+// httpErrorStyle
+var httpRedirectStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FFD700"))
+
+// cellPadding is the table's default horizontal padding on each side of
+// a cell
+const cellPadding = 1
+
+// columnSpan is the range of display columns a table column's text
+// occupies in rendered rows. The zero span covers nothing.
+type columnSpan struct {
+	start, end int
+}
+
+// spanOf locates the column with the given title in rendered rows
+func spanOf(columns []table.Column, title string) columnSpan {
+	x := 0
+	for _, c := range columns {
+		if c.Title == title {
+			return columnSpan{start: x + cellPadding, end: x + cellPadding + c.Width}
+		}
+		x += c.Width + 2*cellPadding
+	}
+	return columnSpan{}
+}
+
+// httpStatusStyle picks the style for an HTTP status code
+func httpStatusStyle(code int) (lipgloss.Style, bool) {
+	switch {
+	case code >= 200 && code < 300:
+		return httpOKStyle, true
+	case code >= 300 && code < 400:
+		return httpRedirectStyle, true
+	case code >= 400 && code < 600:
+		return httpErrorStyle, true
+	}
+	return lipgloss.Style{}, false
+}
+
+// colorizeHTTPStatus colors the status code in a rendered row's HTTP
+// cell. Unchecked ports show "-" and are left alone.
+func colorizeHTTPStatus(line string, span columnSpan) string {
+	if span.end == 0 {
+		return line
+	}
+	cell := ansi.Cut(line, span.start, span.end)
+	text := strings.TrimRight(cell, " ")
+	code, err := strconv.Atoi(text)
+	if err != nil {
+		return line
+	}
+	style, ok := httpStatusStyle(code)
+	if !ok {
+		return line
+	}
+	return ansi.Truncate(line, span.start, "") + style.Render(text) +
+		cell[len(text):] + ansi.TruncateLeft(line, span.end, "")
+}
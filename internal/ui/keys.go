@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap binds gaze's actions to keys. The table's own navigation keys
+// (arrows, j/k, g/G, pgup/pgdown) are handled by the table itself.
+type KeyMap struct {
+	Quit          key.Binding
+	Kill          key.Binding
+	Select        key.Binding
+	ForceKill     key.Binding
+	KillWait      key.Binding
+	KillRange     key.Binding
+	ReniceDown    key.Binding
+	ReniceUp      key.Binding
+	Suspend       key.Binding
+	Refresh       key.Binding
+	Slower        key.Binding
+	Faster        key.Binding
+	Sort          key.Binding
+	SecondarySort key.Binding
+	Order         key.Binding
+	History       key.Binding
+	ClearHistory  key.Binding
+	Stats         key.Binding
+	Top           key.Binding
+	Split         key.Binding
+	EventFilter   key.Binding
+	Metrics       key.Binding
+	AgeBars       key.Binding
+	ServiceNames  key.Binding
+	Compact       key.Binding
+	Pin           key.Binding
+	Ignore        key.Binding
+	Containers    key.Binding
+	OnlyProcess   key.Binding
+	Search        key.Binding
+	Detail        key.Binding
+	Open          key.Binding
+	Explain       key.Binding
+	QueryPort     key.Binding
+	Export        key.Binding
+	ExportHistory key.Binding
+	Capabilities  key.Binding
+}
+
+// DefaultKeyMap returns the default bindings. Kill lives on x so that k
+// stays free for vim-style navigation in the table, and still asks for
+// confirmation so a reflexive keypress can't kill anything. It sends
+// SIGTERM; ForceKill sends SIGKILL for processes that ignore it.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:          binding("Quit", "q", "ctrl+c", "esc"),
+		Kill:          binding("Kill (asks y/n)", "x"),
+		Select:        key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "Select")),
+		ForceKill:     binding("Force kill", "K"),
+		KillWait:      binding("Kill & wait", "w", "W"),
+		KillRange:     binding("Kill range", "X"),
+		ReniceDown:    binding("Lower priority", "n"),
+		ReniceUp:      binding("Raise priority", "N"),
+		Suspend:       binding("Pause/resume", "."),
+		Refresh:       binding("Refresh", "r", "R"),
+		Slower:        binding("Scan less often", "+", "="),
+		Faster:        binding("Scan more often", "-", "_"),
+		Sort:          binding("Sort", "s"),
+		SecondarySort: binding("Then sort", "S"),
+		Order:         binding("Order", "a", "A"),
+		History:       binding("History", "h", "H"),
+		ClearHistory:  binding("Clear history", "ctrl+r"),
+		Stats:         binding("Stats", "T"),
+		Top:           binding("Top talkers", "t"),
+		Split:         binding("Split", "v", "V"),
+		EventFilter:   binding("Filter events", "f", "F"),
+		Metrics:       binding("Metrics", "m", "M"),
+		AgeBars:       binding("Age bars", "B"),
+		ServiceNames:  binding("Service names", "l"),
+		Compact:       binding("Compact", "z", "Z"),
+		Pin:           binding("Pin", "p", "P"),
+		Ignore:        binding("Ignore", "I"),
+		Containers:    binding("Containers", "c", "C"),
+		OnlyProcess:   binding("Only this process", "O"),
+		Search:        binding("Search", "/"),
+		Detail:        binding("Details", "d"),
+		Open:          binding("Open in browser", "o"),
+		Explain:       binding("Explain", "i"),
+		QueryPort:     binding("Is port free", "?"),
+		Export:        binding("Export", "e", "E"),
+		ExportHistory: binding("Exports", "L"),
+		Capabilities:  binding("Capabilities", "D"),
+	}
+}
+
+// binding creates a key binding whose help shows the first key
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keys[0], desc))
+}
+
+// actions maps the names used in the settings file to bindings
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":           &k.Quit,
+		"kill":           &k.Kill,
+		"select":         &k.Select,
+		"force_kill":     &k.ForceKill,
+		"kill_wait":      &k.KillWait,
+		"kill_range":     &k.KillRange,
+		"renice_down":    &k.ReniceDown,
+		"renice_up":      &k.ReniceUp,
+		"suspend":        &k.Suspend,
+		"refresh":        &k.Refresh,
+		"slower":         &k.Slower,
+		"faster":         &k.Faster,
+		"sort":           &k.Sort,
+		"secondary_sort": &k.SecondarySort,
+		"order":          &k.Order,
+		"history":        &k.History,
+		"clear_history":  &k.ClearHistory,
+		"stats":          &k.Stats,
+		"top":            &k.Top,
+		"split":          &k.Split,
+		"event_filter":   &k.EventFilter,
+		"metrics":        &k.Metrics,
+		"age_bars":       &k.AgeBars,
+		"service_names":  &k.ServiceNames,
+		"compact":        &k.Compact,
+		"pin":            &k.Pin,
+		"ignore":         &k.Ignore,
+		"containers":     &k.Containers,
+		"only_process":   &k.OnlyProcess,
+		"search":         &k.Search,
+		"detail":         &k.Detail,
+		"open":           &k.Open,
+		"explain":        &k.Explain,
+		"query_port":     &k.QueryPort,
+		"export":         &k.Export,
+		"export_history": &k.ExportHistory,
+		"capabilities":   &k.Capabilities,
+	}
+}
+
+// Override rebinds actions by name, e.g. {"kill": ["ctrl+k"]}, as read
+// from the "keys" section of the settings file
+func (k *KeyMap) Override(overrides map[string][]string) error {
+	actions := k.actions()
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			names := make([]string, 0, len(actions))
+			for n := range actions {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown key binding %q (use one of %s)", name, strings.Join(names, ", "))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("key binding %q has no keys", name)
+		}
+		*b = binding(b.Help().Desc, keys...)
+	}
+	return nil
+}
+
+// helpText renders bindings as "key: Action" pairs for the help footer
+func helpText(prefix string, bindings ...key.Binding) string {
+	parts := []string{prefix}
+	for _, b := range bindings {
+		parts = append(parts, fmt.Sprintf("%s: %s", b.Help().Key, b.Help().Desc))
+	}
+	return strings.Join(parts, " • ")
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Polling used by kill-and-wait while a killed process releases its port
+const (
+	portFreeTimeout      = 10 * time.Second
+	portFreePollInterval = 250 * time.Millisecond
+)
+
+// portFreeMsg reports whether a port being waited on is still bound
+type portFreeMsg struct {
+	port int
+	free bool
+	err  error
+}
+
+// killAndWait kills the process holding a port and then polls until the
+// port is released, so the rescan that follows no longer shows it bound
+func (m *Model) killAndWait(p scanner.PortInfo) tea.Cmd {
+	if p.PID == 0 || !m.signalPermitted(p, "kill") {
+		return nil
+	}
+	if !m.actionAllowed(fmt.Sprintf("kill PID %d (%s) and wait for :%d to free", p.PID, p.Process, p.Port)) {
+		return nil
+	}
+
+	if err := scanner.KillProcess(p.PID); err != nil && !scanner.IsProcessGone(err) {
+		m.err = killFailure(err)
+		return nil
+	}
+
+	m.waitingPort = p.Port
+	m.waitDeadline = time.Now().Add(portFreeTimeout)
+	return checkPortFree(p.Port, 0)
+}
+
+// checkPortFree checks whether port has been released after delay
+func checkPortFree(port int, delay time.Duration) tea.Cmd {
+	check := func() tea.Msg {
+		listening, err := scanner.IsPortListening(port)
+		return portFreeMsg{port: port, free: !listening, err: err}
+	}
+	if delay == 0 {
+		return check
+	}
+	return tea.Tick(delay, func(time.Time) tea.Msg { return check() })
+}
+
+// handlePortFree follows up on a port check, polling again until the port
+// is free or the timeout passes, then rescanning
+func (m *Model) handlePortFree(msg portFreeMsg) tea.Cmd {
+	if msg.port != m.waitingPort {
+		return nil
+	}
+
+	switch {
+	case msg.err != nil:
+		m.err = msg.err
+	case msg.free:
+		m.setStatus(fmt.Sprintf("Port :%d is free", msg.port))
+	case time.Now().After(m.waitDeadline):
+		m.err = fmt.Errorf("port :%d was not freed within %s", msg.port, portFreeTimeout)
+	default:
+		return checkPortFree(msg.port, portFreePollInterval)
+	}
+
+	m.waitingPort = 0
+	return scanPorts(m.scanConfig)
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// notifyTimeout bounds the notifier, so a stuck notification daemon can't
+// pile up notifier processes
+const notifyTimeout = 5 * time.Second
+
+// notify shows a desktop notification with notify-send, or osascript on
+// macOS. It waits for the notifier, so call it off the UI goroutine.
+func notify(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=gaze", title, body)
+	}
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("desktop notification %w after %s", scanner.ErrCommandTimeout, notifyTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("desktop notification failed: %w %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// portQueryMsg is the result of a targeted check for a port missing from
+// the last scan
+type portQueryMsg struct {
+	port      int
+	listening bool
+	err       error
+}
+
+// queryPort answers whether a port is free, using the last scan when it
+// lists the port and a targeted check otherwise
+func (m *Model) queryPort(value string) tea.Cmd {
+	r, err := scanner.ParsePortRange(value)
+	if err != nil || r.Start != r.End {
+		m.err = fmt.Errorf("enter a single port number, got %q", value)
+		return nil
+	}
+	port := r.Start
+
+	for _, p := range m.allPorts {
+		if p.Port == port {
+			m.portAnswer = describePortInUse(p)
+			return nil
+		}
+	}
+
+	// Not in the last scan; it may have opened since or be ignored
+	m.portAnswer = fmt.Sprintf("Checking port %d...", port)
+	return func() tea.Msg {
+		listening, err := scanner.IsPortListening(port)
+		return portQueryMsg{port: port, listening: listening, err: err}
+	}
+}
+
+// handlePortQuery reports the result of a targeted port check
+func (m *Model) handlePortQuery(msg portQueryMsg) tea.Cmd {
+	switch {
+	case msg.err != nil:
+		m.portAnswer = ""
+		m.err = msg.err
+	case msg.listening:
+		m.portAnswer = fmt.Sprintf("✗ Port %d is in use, but wasn't in the last scan. Rescanning for details.", msg.port)
+		return scanPorts(m.scanConfig)
+	default:
+		m.portAnswer = fmt.Sprintf("✓ Port %d is free.", msg.port)
+	}
+	return nil
+}
+
+// describePortInUse says what holds a port found in the scan
+func describePortInUse(p scanner.PortInfo) string {
+	if p.PID == 0 {
+		return fmt.Sprintf("✗ Port %d is in use by a process gaze can't see.", p.Port)
+	}
+	return fmt.Sprintf("✗ Port %d is in use by %s (PID %d).", p.Port, p.Process, p.PID)
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// Table words for process states worth flagging. They are upper case so
+// they can be picked out of rendered rows for styling.
+const (
+	stoppedState = "STOPPED"
+	zombieState  = "ZOMBIE"
+)
+
+// procStateStyle highlights stopped and zombie processes
+var procStateStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#000000")).
+	Background(lipgloss.Color("#FFA500")).
+	Bold(true)
+
+// procStateWords maps gopsutil's process states to readable words
+var procStateWords = map[string]string{
+	"running":           "running",
+	"sleep":             "sleeping",
+	"idle":              "idle",
+	"wait":              "waiting",
+	"lock":              "locked",
+	"blocked":           "disk wait",
+	scanner.ProcStopped: stoppedState,
+	scanner.ProcZombie:  zombieState,
+}
+
+// procStateWord describes a process state, or "-" if unknown
+func procStateWord(state string) string {
+	if word, ok := procStateWords[state]; ok {
+		return word
+	}
+	if state == "" {
+		return "-"
+	}
+	return state
+}
+
+// colorizeProcState styles the stopped and zombie states in a rendered
+// table row. Like the diff markers, this runs after rendering as the
+// table can't measure styled cells.
+func colorizeProcState(line string) string {
+	for _, word := range []string{stoppedState, zombieState} {
+		if i := strings.Index(line, " "+word+" "); i >= 0 {
+			i++
+			return line[:i] + procStateStyle.Render(word) + line[i+len(word):]
+		}
+	}
+	return line
+}
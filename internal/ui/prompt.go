@@ -0,0 +1,327 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// inputMode identifies what the text prompt is collecting
+type inputMode int
+
+const (
+	inputNone inputMode = iota
+	inputKillRange
+	inputPortQuery
+	inputFilter
+)
+
+// label returns the prompt shown before the typed text
+func (i inputMode) label() string {
+	switch i {
+	case inputKillRange:
+		return "Kill port range (end with ! for SIGKILL)"
+	case inputPortQuery:
+		return "Is this port free? Port"
+	case inputFilter:
+		return "Search process, port, PID or user"
+	}
+	return ""
+}
+
+// confirmation is a pending action awaiting a y/n answer
+type confirmation struct {
+	prompt string
+	onYes  func(m *Model) tea.Cmd
+}
+
+// handlePromptKey routes keys to an active text prompt or confirmation.
+// It reports whether the key was consumed.
+func (m *Model) handlePromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.confirm != nil {
+		c := m.confirm
+		m.confirm = nil
+		if msg.String() == "y" || msg.String() == "Y" {
+			return true, c.onYes(m)
+		}
+		m.setStatus("Cancelled")
+		return true, nil
+	}
+
+	if m.input == inputNone {
+		return false, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		if m.input == inputFilter {
+			m.setFilter("")
+		}
+		m.input = inputNone
+		m.inputBuffer = ""
+	case tea.KeyEnter:
+		mode, value := m.input, m.inputBuffer
+		m.input = inputNone
+		m.inputBuffer = ""
+		return true, m.submitInput(mode, value)
+	case tea.KeyBackspace:
+		if m.inputBuffer != "" {
+			m.inputBuffer = m.inputBuffer[:len(m.inputBuffer)-1]
+		}
+	case tea.KeyRunes:
+		m.inputBuffer += string(msg.Runes)
+	}
+	// The search filters the table as it is typed
+	if m.input == inputFilter {
+		m.setFilter(m.inputBuffer)
+	}
+	return true, nil
+}
+
+// startInput opens the text prompt for mode
+func (m *Model) startInput(mode inputMode) {
+	m.input = mode
+	m.inputBuffer = ""
+}
+
+// submitInput acts on a completed text prompt
+func (m *Model) submitInput(mode inputMode, value string) tea.Cmd {
+	switch mode {
+	case inputKillRange:
+		m.confirmKillRange(value)
+	case inputPortQuery:
+		return m.queryPort(value)
+	case inputFilter:
+		m.setFilter(value)
+	}
+	return nil
+}
+
+// renderPrompt renders the active text prompt or confirmation, if any
+func (m Model) renderPrompt() string {
+	if m.confirm != nil {
+		return warningStyle.Render(m.confirm.prompt+" (y/n)") + "\n"
+	}
+	if m.input != inputNone {
+		return statusStyle.Render(fmt.Sprintf("%s: %s_", m.input.label(), m.inputBuffer)) + "\n"
+	}
+	return ""
+}
+
+// signalNames names the signals the kill keys send
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGTERM: "SIGTERM",
+	syscall.SIGKILL: "SIGKILL",
+}
+
+// confirmKill asks for confirmation to send sig to the process holding p:
+// SIGTERM to let it shut down cleanly, or SIGKILL to end it at once
+func (m *Model) confirmKill(p scanner.PortInfo, sig syscall.Signal) {
+	if p.PID == 0 || !m.signalPermitted(p, "kill") {
+		return
+	}
+	on := socketTarget(p)
+	action := fmt.Sprintf("kill PID %d (%s) on %s", p.PID, p.Process, on)
+	sent := fmt.Sprintf("Killed PID %d (%s)", p.PID, p.Process)
+	if scanner.SignalsSupported() {
+		action += " with " + signalNames[sig]
+		sent = fmt.Sprintf("Sent %s to PID %d (%s)", signalNames[sig], p.PID, p.Process)
+	}
+	if m.refuseReadOnly(action) {
+		return
+	}
+	m.confirm = &confirmation{
+		prompt: "Really " + action + "?",
+		onYes: func(m *Model) tea.Cmd {
+			if !m.actionAllowed(action) {
+				return nil
+			}
+			// Scans keep running while the prompt is open. If the process
+			// let go of the socket meanwhile, its PID may even belong to
+			// something else by now.
+			if !m.stillHolds(p) {
+				m.setStatus(fmt.Sprintf("PID %d no longer holds %s; nothing was killed", p.PID, on))
+				return scanPorts(m.scanConfig)
+			}
+			err := scanner.KillProcessWithSignal(p.PID, sig)
+			if err != nil && !scanner.IsProcessGone(err) {
+				m.err = killFailure(err)
+				return nil
+			}
+			if err == nil {
+				m.setStatus(sent)
+			}
+			// Immediately rescan after killing, or silently if the
+			// process had already exited
+			return scanPorts(m.scanConfig)
+		},
+	}
+}
+
+// confirmClearHistory asks for confirmation to wipe the port history, then
+// rescans so the tracker starts over from the ports open now
+func (m *Model) confirmClearHistory() {
+	stats := m.historyTracker.GetStats()
+	m.confirm = &confirmation{
+		prompt: fmt.Sprintf("Really clear the history of %d ports and %d events?", stats.TotalPortsTracked, stats.TotalEvents),
+		onYes: func(m *Model) tea.Cmd {
+			m.historyTracker.Reset()
+			// Treat the next scan as the first, so the ports already open
+			// aren't highlighted as new or auto-killed
+			m.baselineAt = time.Time{}
+			if m.viewMode == ViewHistory {
+				m.updateHistoryTable()
+			}
+			m.setStatus("history cleared")
+			return scanPorts(m.scanConfig)
+		},
+	}
+}
+
+// socketTarget names a port's socket in kill messages: ":8080" or a unix
+// socket's path
+func socketTarget(p scanner.PortInfo) string {
+	if p.SocketType == scanner.SocketUnix {
+		return p.SocketPath
+	}
+	return ":" + p.Endpoint()
+}
+
+// stillHolds reports whether the latest scan still shows p's process
+// holding its socket
+func (m *Model) stillHolds(p scanner.PortInfo) bool {
+	for _, q := range m.allPorts {
+		if q.PID == p.PID && q.SocketType == p.SocketType && q.Endpoint() == p.Endpoint() {
+			return true
+		}
+	}
+	return false
+}
+
+// signalPermitted reports whether gaze may signal the process holding p,
+// e.g. to kill it, explaining why not otherwise
+func (m *Model) signalPermitted(p scanner.PortInfo, verb string) bool {
+	if p.CanKill {
+		return true
+	}
+	m.err = fmt.Errorf("can't %s PID %d (%s): it belongs to another user; run gaze as that user or root", verb, p.PID, p.Process)
+	return false
+}
+
+// confirmKillRange asks for confirmation to kill every process listening
+// on a port in the given range, with SIGTERM or, if spec ends in "!",
+// SIGKILL
+func (m *Model) confirmKillRange(spec string) {
+	spec, force := strings.CutSuffix(strings.TrimSpace(spec), "!")
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	r, err := scanner.ParsePortRange(spec)
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	var targets []scanner.PortInfo
+	for _, p := range m.allPorts {
+		if r.Contains(p.Port) && p.PID != 0 {
+			targets = append(targets, p)
+		}
+	}
+	if len(targets) == 0 {
+		m.setStatus(fmt.Sprintf("No processes are listening on ports %s", r))
+		return
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Port < targets[j].Port })
+
+	targets, denied := m.permittedTargets(targets, fmt.Sprintf("on ports %s", r))
+	if len(targets) == 0 {
+		return
+	}
+
+	descriptions := make([]string, len(targets))
+	for i, p := range targets {
+		descriptions[i] = fmt.Sprintf(":%d %s (PID %d)", p.Port, p.Process, p.PID)
+	}
+	action := fmt.Sprintf("kill %d process(es) on ports %s: %s", scanner.CountProcesses(targets), r, strings.Join(descriptions, ", "))
+	if scanner.SignalsSupported() {
+		action += " with " + signalNames[sig]
+	}
+	if m.refuseReadOnly(action) {
+		return
+	}
+
+	m.confirm = &confirmation{
+		prompt: "Really " + action + "?" + deniedNote(denied),
+		onYes: func(m *Model) tea.Cmd {
+			if !m.actionAllowed(action) {
+				return nil
+			}
+			return m.killPorts(targets, sig)
+		},
+	}
+}
+
+// permittedTargets drops the ports whose processes gaze may not signal,
+// which confirmKill refuses too, and returns how many were dropped. If
+// none are left it explains why, naming the ports by which.
+func (m *Model) permittedTargets(targets []scanner.PortInfo, which string) ([]scanner.PortInfo, int) {
+	var permitted []scanner.PortInfo
+	for _, p := range targets {
+		if p.CanKill {
+			permitted = append(permitted, p)
+		}
+	}
+	denied := len(targets) - len(permitted)
+	if len(permitted) == 0 {
+		m.err = fmt.Errorf("can't kill the processes %s: they belong to other users; run gaze as those users or root", which)
+	}
+	return permitted, denied
+}
+
+// deniedNote tells a kill prompt how many processes it leaves out because
+// they belong to other users
+func deniedNote(denied int) string {
+	if denied == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (skipping %d owned by other users)", denied)
+}
+
+// killPorts sends sig to the processes holding the given ports and reports
+// the outcome for each port, with how many succeeded and failed
+func (m *Model) killPorts(targets []scanner.PortInfo, sig syscall.Signal) tea.Cmd {
+	pids := make([]int32, len(targets))
+	for i, p := range targets {
+		pids[i] = p.PID
+	}
+	results := scanner.KillMultipleProcesses(pids, sig)
+	verb := "Killed"
+	if scanner.SignalsSupported() && sig != syscall.SIGKILL {
+		verb = "Sent " + signalNames[sig] + " to"
+	}
+
+	var killed, failed []string
+	for _, p := range targets {
+		switch err := results[p.PID]; {
+		case err == nil, scanner.IsProcessGone(err):
+			killed = append(killed, socketTarget(p))
+		default:
+			failed = append(failed, fmt.Sprintf("%s (%v)", socketTarget(p), killFailure(err)))
+		}
+	}
+
+	if len(killed) > 0 {
+		m.setStatus(fmt.Sprintf("%s %d of %d: %s", verb, len(killed), len(targets), strings.Join(killed, ", ")))
+	}
+	if len(failed) > 0 {
+		m.err = fmt.Errorf("failed to kill %d of %d: %s", len(failed), len(targets), strings.Join(failed, ", "))
+	}
+	return scanPorts(m.scanConfig)
+}
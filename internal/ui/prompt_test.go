@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestKillPromptReadOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		readOnly   bool
+		dryRun     bool
+		selected   bool // Kill the selected ports rather than the one under the cursor
+		wantPrompt bool
+	}{
+		{"kill", false, false, false, true},
+		{"kill read-only", true, false, false, false},
+		{"kill dry run", false, true, false, true},
+		{"kill selected", false, false, true, true},
+		{"kill selected read-only", true, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports := listening(3000, 4000)
+			for i := range ports {
+				ports[i].CanKill = true
+			}
+			m := Model{
+				allPorts: ports,
+				readOnly: tt.readOnly,
+				dryRun:   tt.dryRun,
+				selected: map[selectionKey]bool{selectionKeyOf(ports[0]): true, selectionKeyOf(ports[1]): true},
+			}
+
+			if tt.selected {
+				m.confirmKillSelected()
+			} else {
+				m.confirmKill(ports[0], syscall.SIGTERM)
+			}
+
+			if got := m.confirm != nil; got != tt.wantPrompt {
+				t.Errorf("prompted %v, want %v", got, tt.wantPrompt)
+			}
+			if got := m.err != nil; got != tt.readOnly {
+				t.Errorf("error %v, want refusal %v", m.err, tt.readOnly)
+			}
+		})
+	}
+}
+
+func TestConfirmKillRangeSignal(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantSignal string
+	}{
+		{"3000-4000", "SIGTERM"},
+		{"3000-4000!", "SIGKILL"},
+		{" 3000-4000! ", "SIGKILL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			ports := listening(3000, 4000)
+			for i := range ports {
+				ports[i].CanKill = true
+			}
+			m := Model{allPorts: ports}
+			m.confirmKillRange(tt.spec)
+			if m.confirm == nil {
+				t.Fatalf("no prompt, error %v", m.err)
+			}
+			if !scanner.SignalsSupported() {
+				return
+			}
+			if !strings.Contains(m.confirm.prompt, "kill 2 process(es)") || !strings.HasSuffix(m.confirm.prompt, "with "+tt.wantSignal+"?") {
+				t.Errorf("prompt %q, want 2 processes killed with %s", m.confirm.prompt, tt.wantSignal)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// selectedMarker prefixes ports selected for a batch kill
+const selectedMarker = "✔ "
+
+// selectionKey identifies a selected socket. Unlike history.PortKey it
+// tells unix sockets apart by path.
+type selectionKey struct {
+	socketType, endpoint string
+}
+
+// selectionKeyOf returns the key a port is selected under
+func selectionKeyOf(p scanner.PortInfo) selectionKey {
+	return selectionKey{socketType: p.SocketType, endpoint: p.Endpoint()}
+}
+
+// toggleSelected marks or unmarks a port for a batch kill. The selection
+// is kept by port rather than row, so it survives rescans and re-sorts.
+func (m *Model) toggleSelected(p scanner.PortInfo) {
+	if p.PID == 0 {
+		m.setStatus(fmt.Sprintf("No process to kill on %s", socketTarget(p)))
+		return
+	}
+	k := selectionKeyOf(p)
+	if m.selected[k] {
+		delete(m.selected, k)
+	} else {
+		m.selected[k] = true
+	}
+}
+
+// pruneSelection unmarks ports that closed, so a process opening one of
+// them later isn't killed along with the rest
+func (m *Model) pruneSelection() {
+	if len(m.selected) == 0 {
+		return
+	}
+	open := make(map[selectionKey]bool, len(m.allPorts))
+	for _, p := range m.allPorts {
+		open[selectionKeyOf(p)] = true
+	}
+	for k := range m.selected {
+		if !open[k] {
+			delete(m.selected, k)
+		}
+	}
+}
+
+// clearSelection unmarks every selected port
+func (m *Model) clearSelection() {
+	clear(m.selected)
+	m.updateTableRows()
+}
+
+// confirmKillSelected asks for confirmation to kill the processes holding
+// the selected ports, then clears the selection
+func (m *Model) confirmKillSelected() {
+	var targets []scanner.PortInfo
+	for _, p := range m.allPorts {
+		if m.selected[selectionKeyOf(p)] && p.PID != 0 {
+			targets = append(targets, p)
+		}
+	}
+	if len(targets) == 0 {
+		m.setStatus("None of the selected ports are open anymore")
+		m.clearSelection()
+		return
+	}
+	sort.Slice(targets, func(i, j int) bool { return m.comparePorts(targets[i], targets[j], SortByPort) < 0 })
+	targets, denied := m.permittedTargets(targets, "selected")
+	if len(targets) == 0 {
+		return
+	}
+
+	descriptions := make([]string, len(targets))
+	for i, p := range targets {
+		descriptions[i] = fmt.Sprintf("%s %s (PID %d)", socketTarget(p), p.Process, p.PID)
+	}
+	action := fmt.Sprintf("kill %d selected process(es): %s", scanner.CountProcesses(targets), strings.Join(descriptions, ", "))
+	if scanner.SignalsSupported() {
+		action += " with SIGTERM"
+	}
+	if m.refuseReadOnly(action) {
+		return
+	}
+
+	m.confirm = &confirmation{
+		prompt: "Really " + action + "?" + deniedNote(denied),
+		onYes: func(m *Model) tea.Cmd {
+			if !m.actionAllowed(action) {
+				return nil
+			}
+			cmd := m.killPorts(targets, syscall.SIGTERM)
+			m.clearSelection()
+			return cmd
+		},
+	}
+}
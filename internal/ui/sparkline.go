@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/junjiang/gaze/internal/history"
+)
+
+// sparkTicks draws sparkline values from lowest to highest
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as one tick per value, scaled against the
+// largest of them, or against floor if that is larger so that idle noise
+// stays flat
+func sparkline(values []float64, floor float64) string {
+	largest := floor
+	for _, v := range values {
+		largest = max(largest, v)
+	}
+	if largest <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := int(v / largest * float64(len(sparkTicks)-1))
+		b.WriteRune(sparkTicks[min(max(level, 0), len(sparkTicks)-1)])
+	}
+	return b.String()
+}
+
+// cpuSparkline draws the CPU usage of a port's recent samples, scaled to
+// at least 10% so a mostly idle process doesn't look busy
+func cpuSparkline(samples []history.Sample) string {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.CPUPercent
+	}
+	return sparkline(values, 10)
+}
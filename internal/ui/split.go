@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Live event feed sizing in split view
+const (
+	minSplitFeedRows = 3
+	// splitFeedShare is the fraction of the terminal height given to the feed
+	splitFeedShare = 3
+)
+
+// splitFeedRows returns how many events the split view's feed shows for
+// the current terminal height
+func (m Model) splitFeedRows() int {
+	if m.height == 0 {
+		return eventFeedSize
+	}
+	return max(m.height/splitFeedShare, minSplitFeedRows)
+}
+
+// renderSplit stacks the ports table above the live event feed
+func (m Model) renderSplit() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		colorizeDiffRows(m.table.View(), spanOf(m.table.Columns(), "HTTP")),
+		strings.TrimSuffix(m.renderEventFeed(m.splitFeedRows()), "\n"),
+	)
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/junjiang/gaze/internal/history"
+)
+
+// updateStatsTable fills the table with per-process aggregates, the
+// processes that churn the most ports first
+func (m *Model) updateStatsTable() {
+	// Clear rows first to prevent index out of range panic when column count changes
+	m.table.SetRows([]table.Row{})
+
+	columns := []table.Column{
+		{Title: "Process", Width: 25},
+		{Title: "Ports", Width: 8},
+		{Title: "Active", Width: 8},
+		{Title: "Restarts", Width: 10},
+	}
+	m.table.SetColumns(columns)
+
+	all := m.historyTracker.GetProcessStats()
+	stats := make([]history.ProcessStats, 0, len(all))
+	for _, s := range all {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Restarts != stats[j].Restarts {
+			return stats[i].Restarts > stats[j].Restarts
+		}
+		if stats[i].PortsOpened != stats[j].PortsOpened {
+			return stats[i].PortsOpened > stats[j].PortsOpened
+		}
+		return stats[i].Process < stats[j].Process
+	})
+
+	rows := make([]table.Row, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, table.Row{
+			s.Process,
+			fmt.Sprintf("%d", s.PortsOpened),
+			fmt.Sprintf("%d", s.ActivePorts),
+			fmt.Sprintf("%d", s.Restarts),
+		})
+	}
+	m.table.SetRows(rows)
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// topTalkersCount is how many processes the top talkers view ranks
+const topTalkersCount = 10
+
+// topBarWidth is the width in cells of the top talkers view's bars
+const topBarWidth = 20
+
+// topTalker is a process holding listening ports, with its usage
+type topTalker struct {
+	pid        int32
+	process    string
+	ports      []string
+	cpuPercent float64
+	memoryMB   float64
+}
+
+// value returns the talker's usage in the ranking metric
+func (t topTalker) value(byMemory bool) float64 {
+	if byMemory {
+		return t.memoryMB
+	}
+	return t.cpuPercent
+}
+
+// topTalkers groups the listed ports by process and ranks the processes
+// by CPU or memory, heaviest first
+func (m Model) topTalkers() []topTalker {
+	byPID := make(map[int32]*topTalker)
+	var talkers []*topTalker
+	for _, p := range m.ports {
+		if p.PID == 0 {
+			continue
+		}
+		t, ok := byPID[p.PID]
+		if !ok {
+			t = &topTalker{pid: p.PID, process: p.Process, cpuPercent: p.CPUPercent, memoryMB: p.MemoryMB}
+			byPID[p.PID] = t
+			talkers = append(talkers, t)
+		}
+		t.ports = append(t.ports, p.Endpoint())
+	}
+
+	ranked := make([]topTalker, len(talkers))
+	for i, t := range talkers {
+		sort.Strings(t.ports)
+		ranked[i] = *t
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].value(m.topByMemory) > ranked[j].value(m.topByMemory)
+	})
+	if len(ranked) > topTalkersCount {
+		ranked = ranked[:topTalkersCount]
+	}
+	return ranked
+}
+
+// updateTopTable fills the table with the heaviest processes among those
+// holding ports, with a bar relative to the heaviest
+func (m *Model) updateTopTable() {
+	// Clear rows first to prevent index out of range panic when column count changes
+	m.table.SetRows([]table.Row{})
+
+	metric := "CPU%"
+	if m.topByMemory {
+		metric = "Mem(MB)"
+	}
+	m.table.SetColumns([]table.Column{
+		{Title: "#", Width: 3},
+		{Title: "Process", Width: 20},
+		{Title: "PID", Width: 8},
+		{Title: "Ports", Width: 20},
+		{Title: metric, Width: 8},
+		{Title: "", Width: topBarWidth + 1},
+	})
+
+	talkers := m.topTalkers()
+	var heaviest float64
+	if len(talkers) > 0 {
+		heaviest = talkers[0].value(m.topByMemory)
+	}
+
+	rows := make([]table.Row, 0, len(talkers))
+	for i, t := range talkers {
+		rows = append(rows, table.Row{
+			fmt.Sprintf("%d", i+1),
+			t.process,
+			fmt.Sprintf("%d", t.pid),
+			strings.Join(t.ports, ", "),
+			fmt.Sprintf("%.1f", t.value(m.topByMemory)),
+			bar(t.value(m.topByMemory), heaviest, topBarWidth),
+		})
+	}
+	m.table.SetRows(rows)
+}
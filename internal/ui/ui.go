@@ -1,14 +1,27 @@
 package ui
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"log/slog"
+	"net/netip"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/junjiang/gaze/internal/baseline"
+	"github.com/junjiang/gaze/internal/config"
 	"github.com/junjiang/gaze/internal/export"
 	"github.com/junjiang/gaze/internal/history"
 	"github.com/junjiang/gaze/internal/scanner"
@@ -37,7 +50,7 @@ var (
 			Foreground(lipgloss.Color("#00D9FF"))
 
 	pidStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
+			Foreground(lipgloss.Color("#888888"))
 
 	processStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#88FF88"))
@@ -53,6 +66,10 @@ var (
 			Foreground(lipgloss.Color("#FF5555")).
 			Bold(true)
 
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFA500")).
+			Bold(true)
+
 	successStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#00FF00")).
 			Bold(true)
@@ -69,30 +86,69 @@ var (
 			Bold(true)
 	// HTTP status styles
 	httpOKStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00FF00"))
+			Foreground(lipgloss.Color("#00FF00"))
 
 	httpErrorStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF5555"))
+			Foreground(lipgloss.Color("#FF5555"))
 
 	// Port type styles
 	wellKnownPortStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF6B6B")).
-		Bold(true)
+				Foreground(lipgloss.Color("#FF6B6B")).
+				Bold(true)
 
 	registeredPortStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#4ECDC4"))
+				Foreground(lipgloss.Color("#4ECDC4"))
 
 	dynamicPortStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#95E1D3"))
+				Foreground(lipgloss.Color("#95E1D3"))
 
 	// Metrics styles
 	metricsStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFA500")))
+			Foreground(lipgloss.Color("#FFA500"))
+)
+
+// Refresh intervals: how often the ports are rescanned by default, and
+// the range the interval can be adjusted within
+const (
+	DefaultRefreshInterval = 3 * time.Second
+	MinRefreshInterval     = 500 * time.Millisecond
+	MaxRefreshInterval     = 60 * time.Second
+)
+
+// refreshSteps are the intervals + and - step through
+var refreshSteps = []time.Duration{
+	500 * time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second,
+	10 * time.Second, 15 * time.Second, 30 * time.Second, 60 * time.Second,
+}
+
+// pinMarker prefixes pinned ports in the table
+const pinMarker = "📌"
+
+// lockMarker prefixes processes gaze lacks permission to kill
+const lockMarker = "🔒"
+
+// jumpTimeout is how long a typed port number is kept before it is cleared
+const jumpTimeout = 1500 * time.Millisecond
 
 type tickMsg time.Time
-type scanResultMsg []scanner.PortInfo
+type scanResultMsg struct {
+	ports     []scanner.PortInfo
+	started   time.Time     // When the scan began
+	duration  time.Duration // Wall-clock time taken by the scan
+	dockerErr error         // Non-fatal Docker lookup failure, if any
+	denied    int           // Sockets whose processes couldn't be read
+}
 type errorMsg struct{ err error }
-type exportSuccessMsg struct{ path string }
+type jumpTimeoutMsg struct{ seq int }
+type exportSuccessMsg struct{ paths []string }
+
+// reniceMsg reports the outcome of renicing a process
+type reniceMsg struct {
+	pid      int32
+	process  string
+	niceness int32
+	err      error
+}
 
 // ViewMode represents the current view
 type ViewMode int
@@ -100,8 +156,31 @@ type ViewMode int
 const (
 	ViewPorts ViewMode = iota
 	ViewHistory
+	ViewStats
+	ViewTop
+	ViewDetail
 )
 
+// viewNames are the names views are saved under in the preferences. The
+// detail view isn't restored, as its port may be gone.
+var viewNames = map[ViewMode]string{
+	ViewPorts:   "ports",
+	ViewHistory: "history",
+	ViewStats:   "stats",
+	ViewTop:     "top",
+}
+
+// savedView returns the view saved under name, or the ports view for an
+// unknown name
+func savedView(name string) ViewMode {
+	for mode, n := range viewNames {
+		if n == name {
+			return mode
+		}
+	}
+	return ViewPorts
+}
+
 // SortColumn represents which column to sort by
 type SortColumn int
 
@@ -109,34 +188,234 @@ const (
 	SortByPort SortColumn = iota
 	SortByPID
 	SortByProcess
+	SortByCPU
+	SortByMemory
+	SortByUptime
+	SortByAddress
+	SortByLatency
+	SortByConnections
+	sortColumnCount
 )
 
+// NoSort is the secondary sort column when none is selected
+const NoSort = sortColumnCount
+
+// String returns the display name of the column
+func (c SortColumn) String() string {
+	switch c {
+	case SortByPort:
+		return "Port"
+	case SortByPID:
+		return "PID"
+	case SortByProcess:
+		return "Process"
+	case SortByCPU:
+		return "CPU"
+	case SortByMemory:
+		return "Memory"
+	case SortByUptime:
+		return "Uptime"
+	case SortByAddress:
+		return "Address"
+	case SortByLatency:
+		return "Latency"
+	case SortByConnections:
+		return "Conns"
+	}
+	return "Unknown"
+}
+
+// ParseSort parses a --sort value: a column, optionally followed by a
+// comma and a secondary column, e.g. "process,mem". The secondary column
+// is NoSort if not given.
+func ParseSort(spec string) (primary, secondary SortColumn, err error) {
+	first, second, hasSecond := strings.Cut(spec, ",")
+	if primary, err = ParseSortColumn(strings.TrimSpace(first)); err != nil {
+		return SortByPort, NoSort, err
+	}
+	if !hasSecond {
+		return primary, NoSort, nil
+	}
+	if secondary, err = ParseSortColumn(strings.TrimSpace(second)); err != nil {
+		return SortByPort, NoSort, err
+	}
+	return primary, secondary, nil
+}
+
+// ParseSortColumn parses a column name as accepted by --sort: port, pid,
+// process, cpu, mem, uptime, addr, latency or conns
+func ParseSortColumn(name string) (SortColumn, error) {
+	switch strings.ToLower(name) {
+	case "port":
+		return SortByPort, nil
+	case "pid":
+		return SortByPID, nil
+	case "process":
+		return SortByProcess, nil
+	case "cpu":
+		return SortByCPU, nil
+	case "mem", "memory":
+		return SortByMemory, nil
+	case "uptime":
+		return SortByUptime, nil
+	case "addr", "address":
+		return SortByAddress, nil
+	case "latency":
+		return SortByLatency, nil
+	case "conns", "connections":
+		return SortByConnections, nil
+	}
+	return SortByPort, fmt.Errorf("unknown sort column %q (use port, pid, process, cpu, mem, uptime, addr, latency or conns)", name)
+}
+
 // Model represents the application state
 type Model struct {
-	ports         []scanner.PortInfo
-	cursor        int
-	table         table.Model
-	err           error
-	lastScan      time.Time
-	isScanning    bool
-	sortColumn    SortColumn
-	sortAscending bool
+	allPorts       []scanner.PortInfo // Every port from the last scan
+	ports          []scanner.PortInfo // Ports passing the filters, as listed
+	cursor         int
+	table          table.Model
+	err            error
+	lastScan       time.Time
+	scanDuration   time.Duration
+	lastScanStart  time.Time // Start of the newest applied scan
+	dockerErr      error
+	isScanning     bool
+	sortColumn     SortColumn
+	secondarySort  SortColumn // Orders ports the sort column ranks equal, or NoSort
+	sortAscending  bool
 	historyTracker *history.Tracker
-	viewMode      ViewMode
-	exportMsg     string
-	exportMsgTime time.Time
-	showMetrics   bool // Toggle for showing CPU/Memory metrics
+	viewMode       ViewMode
+	statusMsg      string // Transient message such as export or action results
+	statusMsgTime  time.Time
+	readOnly       bool // Disable actions that modify processes
+	dryRun         bool // Report process actions without performing them
+	scanConfig     scanner.Config
+	showMetrics    bool   // Toggle for showing CPU/Memory metrics
+	jumpBuffer     string // Digits typed so far for quick-jump to a port
+	jumpSeq        int    // Incremented on each digit so stale timeouts are ignored
+	diff           *scanDiff
+	eventFilter    EventFilter
+	input          inputMode // Active text prompt, if any
+	inputBuffer    string
+	confirm        *confirmation         // Action awaiting y/n confirmation
+	pinned         map[int]bool          // Ports kept at the top regardless of sort
+	selected       map[selectionKey]bool // Ports marked for a batch kill
+	ignored        []scanner.PortRange   // Ports dropped from each scan's results
+	newlyIgnored   []int                 // Ports ignored with the ignore key, added to the saved list
+	compact        bool                  // Compact layout that trades spacing for table rows
+	height         int                   // Terminal height from the last WindowSizeMsg
+	split          bool                  // Show the live event feed under the ports table
+	autoExport     AutoExportOptions
+	lastAutoExport time.Time
+	waitingPort    int                 // Port being waited on after a kill, or 0
+	waitDeadline   time.Time           // When to give up waiting for waitingPort
+	stateOwner     int                 // PID of another instance holding the state lock, or 0
+	prefsSaver     *prefsSaver         // Writes settings changes in order
+	containersOnly bool                // List only ports published by containers
+	pidFilter      int32               // List only ports held by this PID, or 0
+	filter         string              // List only ports matching this search query
+	portRanges     []scanner.PortRange // List only ports in these ranges, if any
+	baseline       baseline.Baseline   // Expected ports; others are flagged
+	highlightNew   time.Duration       // How long newly opened ports stay highlighted
+	baselineAt     time.Time           // When the first scan was applied
+	explain        bool                // Show a plain-words explanation of the highlighted port
+	showAge        bool                // Show the Age bar column
+	showServices   bool                // Show registered service names next to port numbers
+	portAnswer     string              // Answer to the last "is this port free?" query
+	exports        []exportRecord      // Exports made this session, oldest first
+	exportFormats  []export.ExportFormat
+	exportDir      string
+	detail         scanner.PortInfo // Port shown in the detail view
+	detailClosed   bool             // Whether the detail port closed since it was opened
+	detailInfo     scanner.ProcessInfo
+	detailInfoErr  error
+	exportDirErr   error // Why exportDir can't be written to, if it can't
+	showExports    bool
+	maxRows        int                  // Table row limit, or 0
+	autoKill       *autoKiller          // Kills matching processes as they open ports, if set
+	watcher        *portWatcher         // Notifies the desktop when watched ports change, if set
+	alerter        *newPortAlerter      // Alerts on ports opened after startup, if set
+	interval       time.Duration        // How often the ports are rescanned
+	topByMemory    bool                 // Rank the top talkers view by memory rather than CPU
+	capabilities   []scanner.Capability // Optional features probed at startup
+	deniedNoted    bool                 // Whether missing privileges were noted in the status line
+	showCaps       bool
+
+	keys KeyMap
+
+	historySortColumn    history.SortColumn
+	historySortAscending bool
+}
+
+// Options configures the UI at startup
+type Options struct {
+	ReadOnly   bool // Disable actions that modify processes
+	DryRun     bool // Report process actions without performing them
+	ScanConfig scanner.Config
+	// Consecutive scans a new port must be seen in before it is tracked
+	StableScans int
+	AutoExport  AutoExportOptions
+	// PID of another running gaze that holds the state lock. Settings
+	// changes aren't saved while it is set.
+	StateOwner     int
+	ContainersOnly bool  // Start with only container ports listed
+	PID            int32 // Start with only this process's ports listed
+	// List only ports in these ranges, if any. History still tracks every
+	// port.
+	PortRanges []scanner.PortRange
+	// Ports hidden from the table, history and exports. The ignore key
+	// adds to them and to the saved list.
+	Ignored        []scanner.PortRange
+	Baseline       baseline.Baseline // Expected ports, nil to flag nothing
+	SortColumn     SortColumn
+	SecondarySort  SortColumn // NoSort for none
+	SortDescending bool
+	// How long a newly opened port is highlighted; 0 disables it
+	HighlightNew time.Duration
+	// History capacities: events kept overall, ports tracked and events
+	// kept for each port
+	MaxEvents     int
+	MaxHistories  int
+	MaxPortEvents int
+	// Key bindings; the zero value uses DefaultKeyMap
+	KeyMap KeyMap
+	// File the port history is restored from at startup, if set
+	HistoryFile string
+	// Most table rows built, the first by the current sort; 0 for no limit
+	MaxRows int
+	// Kill processes that open ports matching this rule, if set
+	AutoKill *AutoKillRule
+	// Ports whose opening and closing raise desktop notifications
+	Watch []scanner.PortRange
+	// Alert on ports that weren't open at startup
+	AlertNew bool
+	// Where alerted ports are logged, if set
+	AlertLog io.Writer
+	// How often the ports are rescanned; 0 uses DefaultRefreshInterval
+	Interval time.Duration
+	// Formats the export key writes; nil writes JSON and CSV
+	ExportFormats []export.ExportFormat
+	// Directory the export key writes to, created if missing; "" is the
+	// working directory
+	ExportDir string
 }
 
 // InitialModel creates the initial model
-func InitialModel() Model {
+func InitialModel(opts Options) Model {
 	columns := []table.Column{
 		{Title: "Port", Width: 10},
+		{Title: "Proto", Width: 6},
+		{Title: "Address", Width: 16},
 		{Title: "PID", Width: 10},
-		{Title: "Process", Width: 25},
+		{Title: "Process", Width: 20},
+		{Title: "Command", Width: 30},
+		{Title: "User", Width: 10},
+		{Title: "Container", Width: 18},
 		{Title: "HTTP", Width: 8},
-		{Title: "Uptime", Width: 15},
-		{Title: "Status", Width: 10},
+		{Title: "Latency", Width: 10},
+		{Title: "Conns", Width: 7},
+		{Title: "Uptime", Width: 12},
+		{Title: "Status", Width: 11},
 	}
 
 	t := table.New(
@@ -161,22 +440,106 @@ func InitialModel() Model {
 
 	t.SetStyles(s)
 
-	return Model{
+	// f is gaze's event filter key and space selects ports, so the table
+	// pages down with pgdown only
+	t.KeyMap.PageDown = key.NewBinding(key.WithKeys("pgdown"))
+
+	keys := opts.KeyMap
+	if len(keys.Quit.Keys()) == 0 {
+		keys = DefaultKeyMap()
+	}
+	prefs := config.LoadPreferences()
+
+	tracker := history.NewTracker(opts.MaxEvents, opts.MaxHistories, opts.MaxPortEvents, history.DefaultMaxSamples)
+	tracker.SetStableThreshold(opts.StableScans)
+	var loadErr error
+	if opts.HistoryFile != "" {
+		if loadErr = tracker.Load(opts.HistoryFile); loadErr != nil {
+			slog.Warn("restoring history failed", "error", loadErr)
+		}
+	}
+
+	m := Model{
 		ports:          []scanner.PortInfo{},
 		table:          t,
 		lastScan:       time.Now(),
-		sortColumn:     SortByPort,
-		sortAscending:  true,
-		historyTracker: history.NewTracker(1000, 500), // Track last 1000 events, 500 ports
-		viewMode:       ViewPorts,		showMetrics:    false,	}
+		sortColumn:     opts.SortColumn,
+		secondarySort:  opts.SecondarySort,
+		sortAscending:  !opts.SortDescending,
+		historyTracker: tracker,
+		viewMode:       savedView(prefs.View),
+		showMetrics:    false,
+		diff:           newScanDiff(),
+		compact:        prefs.Compact,
+		pinned:         portSet(prefs.Pinned),
+		ignored:        opts.Ignored,
+		selected:       make(map[selectionKey]bool),
+		readOnly:       opts.ReadOnly,
+		dryRun:         opts.DryRun,
+		scanConfig:     opts.ScanConfig,
+		autoExport:     opts.AutoExport,
+		stateOwner:     opts.StateOwner,
+		prefsSaver:     &prefsSaver{},
+		containersOnly: opts.ContainersOnly,
+		pidFilter:      opts.PID,
+		portRanges:     opts.PortRanges,
+		baseline:       opts.Baseline,
+		highlightNew:   opts.HighlightNew,
+		keys:           keys,
+		maxRows:        opts.MaxRows,
+		interval:       cmp.Or(opts.Interval, DefaultRefreshInterval),
+		exportFormats:  opts.ExportFormats,
+		exportDir:      cmp.Or(opts.ExportDir, "."),
+
+		// History defaults to most recently seen first
+		historySortColumn:    history.SortByLastSeen,
+		historySortAscending: false,
+		capabilities:         scanner.ProbeCapabilities(),
+	}
+	// Checked up front so a bad directory shows at startup, not on the
+	// first export
+	m.exportDirErr = export.PrepareDir(m.exportDir)
+	if banner := m.capabilityBanner(); banner != "" {
+		m.setStatus(banner)
+	}
+	if loadErr != nil {
+		m.err = loadErr
+	}
+	if opts.AutoKill != nil {
+		m.autoKill = &autoKiller{rule: *opts.AutoKill}
+		m.autoKill.watch(tracker)
+	}
+	if len(opts.Watch) > 0 {
+		m.watcher = newPortWatcher(opts.Watch, tracker)
+	}
+	if opts.AlertNew {
+		m.alerter = newNewPortAlerter(tracker, opts.AlertLog)
+	}
+	return m
+}
+
+// SaveHistory writes the port history to path so the next session, and
+// gaze --history, can read it
+func (m Model) SaveHistory(path string) error {
+	return m.historyTracker.Save(path)
+}
+
+// HistoryTracker returns the tracker the model records port history in,
+// for sharing it with other readers such as the API server
+func (m Model) HistoryTracker() *history.Tracker {
+	return m.historyTracker
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		tickCmd(),
-		scanPorts(),
-	)
+	cmds := []tea.Cmd{
+		tickCmd(m.interval),
+		scanPorts(m.scanConfig),
+	}
+	if m.autoExport.Interval > 0 {
+		cmds = append(cmds, autoExportTick(m.autoExport.Interval))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages
@@ -185,99 +548,418 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c", "esc":
+		// A port query answer stays up until the next key
+		m.portAnswer = ""
+
+		// Text prompts and confirmations take every key while active
+		if handled, promptCmd := m.handlePromptKey(msg); handled {
+			return m, promptCmd
+		}
+
+		if m.viewMode == ViewDetail {
+			return m, m.handleDetailKey(msg)
+		}
+
+		// Esc clears an active search before it quits
+		if msg.Type == tea.KeyEsc && m.filter != "" && m.viewMode == ViewPorts {
+			m.setFilter("")
+			return m, nil
+		}
+
+		// Quick-jump: typing digits moves the cursor to the matching port
+		if m.viewMode == ViewPorts {
+			if handled, jumpCmd := m.handleJumpKey(msg.String()); handled {
+				return m, jumpCmd
+			}
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 
-		case "k", "K":
-			if len(m.ports) > 0 && m.table.Cursor() < len(m.ports) {
-				selectedPort := m.ports[m.table.Cursor()]
-				if selectedPort.PID != 0 {
-					err := scanner.KillProcess(selectedPort.PID)
-					if err != nil {
-						m.err = fmt.Errorf("failed to kill process %d: %w", selectedPort.PID, err)
-					} else {
-						// Immediately rescan after killing
-						return m, scanPorts()
-					}
+		case key.Matches(msg, m.keys.Kill):
+			// Kill the selected ports if any are marked, else the
+			// highlighted one. Only the ports view's rows are ports.
+			if m.viewMode != ViewPorts {
+				break
+			}
+			if len(m.selected) > 0 {
+				m.confirmKillSelected()
+			} else if m.table.Cursor() < len(m.ports) {
+				m.confirmKill(m.ports[m.table.Cursor()], syscall.SIGTERM)
+			}
+
+		case key.Matches(msg, m.keys.Select):
+			// Mark or unmark the highlighted port for a batch kill
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				m.toggleSelected(m.ports[m.table.Cursor()])
+				m.updateTableRows()
+			}
+
+		case key.Matches(msg, m.keys.ForceKill):
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				m.confirmKill(m.ports[m.table.Cursor()], syscall.SIGKILL)
+			}
+
+		case key.Matches(msg, m.keys.KillWait):
+			// Kill, then wait for the port to actually be released. Other
+			// views list different rows, so the cursor only names a port here.
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) && m.waitingPort == 0 {
+				return m, m.killAndWait(m.ports[m.table.Cursor()])
+			}
+
+		case key.Matches(msg, m.keys.Pin):
+			// Pin or unpin the selected port to the top of the table
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				if m.ports[m.table.Cursor()].SocketType == scanner.SocketUnix {
+					m.setStatus("Only TCP and UDP ports can be pinned")
+					break
+				}
+				port := m.ports[m.table.Cursor()].Port
+				if m.pinned[port] {
+					delete(m.pinned, port)
+				} else {
+					m.pinned[port] = true
+				}
+				m.sortPorts()
+				m.updateTableRows()
+				m.selectPort(port)
+				return m, m.savePreferences()
+			}
+
+		case key.Matches(msg, m.keys.Ignore):
+			// Ignore the selected port from now on
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				if m.ports[m.table.Cursor()].SocketType == scanner.SocketUnix {
+					m.setStatus("Only TCP and UDP ports can be ignored")
+					break
+				}
+				m.ignorePort(m.ports[m.table.Cursor()].Port)
+				return m, m.savePreferences()
+			}
+
+		case key.Matches(msg, m.keys.ExportHistory):
+			// List this session's exports
+			m.showExports = !m.showExports
+
+		case key.Matches(msg, m.keys.Capabilities):
+			// Show what works in this environment
+			m.showCaps = !m.showCaps
+
+		case key.Matches(msg, m.keys.QueryPort):
+			// Ask whether a port is free
+			m.startInput(inputPortQuery)
+
+		case key.Matches(msg, m.keys.KillRange):
+			// Kill every process in a port range
+			if m.viewMode == ViewPorts && !m.refuseReadOnly("kill processes in a port range") {
+				m.startInput(inputKillRange)
+			}
+
+		case key.Matches(msg, m.keys.Suspend):
+			// Stop the selected process, or continue it if stopped
+			if m.viewMode == ViewPorts && len(m.ports) > 0 && m.table.Cursor() < len(m.ports) {
+				return m, m.toggleSuspend(m.ports[m.table.Cursor()])
+			}
+
+		case key.Matches(msg, m.keys.ReniceDown, m.keys.ReniceUp):
+			// Lower or raise the selected process's priority
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				delta := int32(5)
+				if key.Matches(msg, m.keys.ReniceUp) {
+					delta = -5
 				}
+				return m, m.renice(m.ports[m.table.Cursor()], delta)
 			}
 
-		case "r", "R":
+		case key.Matches(msg, m.keys.Refresh):
 			// Manual refresh
-			return m, scanPorts()
+			return m, scanPorts(m.scanConfig)
+
+		case key.Matches(msg, m.keys.Slower):
+			m.stepInterval(1)
+
+		case key.Matches(msg, m.keys.Faster):
+			m.stepInterval(-1)
 
-		case "s", "S":
+		case key.Matches(msg, m.keys.Sort):
 			// Cycle through sort columns
-			m.sortColumn = (m.sortColumn + 1) % 3
-			m.sortPorts()
-			m.updateTableRows()
+			switch m.viewMode {
+			case ViewStats:
+				// Always ordered by churn
+			case ViewTop:
+				m.topByMemory = !m.topByMemory
+				m.updateTopTable()
+			case ViewHistory:
+				m.historySortColumn = m.historySortColumn.Next()
+				m.updateHistoryTable()
+			default:
+				m.sortColumn = (m.sortColumn + 1) % sortColumnCount
+				m.sortPorts()
+				m.updateTableRows()
+				return m, m.savePreferences()
+			}
 
-		case "a", "A":
+		case key.Matches(msg, m.keys.SecondarySort):
+			// Cycle the secondary sort column, including none
+			if m.viewMode == ViewPorts {
+				m.secondarySort = (m.secondarySort + 1) % (NoSort + 1)
+				m.sortPorts()
+				m.updateTableRows()
+				return m, m.savePreferences()
+			}
+
+		case key.Matches(msg, m.keys.Order):
 			// Toggle sort order
-			m.sortAscending = !m.sortAscending
-			m.sortPorts()
-			m.updateTableRows()
+			switch m.viewMode {
+			case ViewStats, ViewTop:
+			case ViewHistory:
+				m.historySortAscending = !m.historySortAscending
+				m.updateHistoryTable()
+			default:
+				m.sortAscending = !m.sortAscending
+				m.sortPorts()
+				m.updateTableRows()
+				return m, m.savePreferences()
+			}
 
-		case "h", "H":
+		case key.Matches(msg, m.keys.History):
 			// Toggle history view
-			if m.viewMode == ViewPorts {
+			if m.viewMode != ViewHistory {
 				m.viewMode = ViewHistory
 				m.updateHistoryTable()
 			} else {
 				m.viewMode = ViewPorts
 				m.updateTableRows()
 			}
+			m.resizeTable()
+			return m, m.savePreferences()
+
+		case key.Matches(msg, m.keys.ClearHistory):
+			m.confirmClearHistory()
 
-		case "m", "M":
+		case key.Matches(msg, m.keys.Stats):
+			// Toggle the per-process stats view
+			if m.viewMode == ViewStats {
+				m.viewMode = ViewPorts
+				m.updateTableRows()
+			} else {
+				m.viewMode = ViewStats
+				m.updateStatsTable()
+			}
+			m.resizeTable()
+			return m, m.savePreferences()
+
+		case key.Matches(msg, m.keys.Top):
+			// Toggle the top talkers view
+			if m.viewMode == ViewTop {
+				m.viewMode = ViewPorts
+				m.updateTableRows()
+			} else {
+				m.viewMode = ViewTop
+				m.updateTopTable()
+			}
+			m.resizeTable()
+			return m, m.savePreferences()
+
+		case key.Matches(msg, m.keys.Detail):
+			// Show everything known about the highlighted port's process
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				return m, m.openDetail(m.ports[m.table.Cursor()])
+			}
+
+		case key.Matches(msg, m.keys.Open):
+			// Open the highlighted web port in the browser
+			if m.viewMode == ViewPorts && m.table.Cursor() < len(m.ports) {
+				m.openInBrowser(m.ports[m.table.Cursor()])
+			}
+
+		case key.Matches(msg, m.keys.Explain):
+			// Explain what is holding the highlighted port
+			if m.viewMode == ViewPorts {
+				m.explain = !m.explain
+			}
+
+		case key.Matches(msg, m.keys.Containers):
+			// Toggle listing only container ports
+			if m.viewMode == ViewPorts {
+				if !m.containersOnly && !m.capabilityEnabled("docker") {
+					m.setStatus("Container details need the docker CLI • press " + m.keys.Capabilities.Help().Key + " for details")
+					break
+				}
+				m.containersOnly = !m.containersOnly
+				m.applyFilters()
+				m.updateTableRows()
+			}
+
+		case key.Matches(msg, m.keys.Search):
+			if m.viewMode == ViewPorts {
+				m.startInput(inputFilter)
+				m.inputBuffer = m.filter
+			}
+
+		case key.Matches(msg, m.keys.OnlyProcess):
+			// Show only the ports of the selected process, or all again
+			if m.viewMode != ViewPorts {
+				break
+			}
+			if m.pidFilter != 0 {
+				m.pidFilter = 0
+			} else if m.table.Cursor() < len(m.ports) && m.ports[m.table.Cursor()].PID != 0 {
+				m.pidFilter = m.ports[m.table.Cursor()].PID
+			}
+			m.applyFilters()
+			m.updateTableRows()
+
+		case key.Matches(msg, m.keys.Split):
+			// Toggle the split layout with a live event feed
+			if m.viewMode == ViewPorts {
+				m.split = !m.split
+				m.resizeTable()
+			}
+
+		case key.Matches(msg, m.keys.EventFilter):
+			// Cycle the history view's event filter
+			if m.viewMode == ViewHistory {
+				m.eventFilter = m.nextEventFilter(m.eventFilter)
+			}
+
+		case key.Matches(msg, m.keys.AgeBars):
+			// Toggle the Age bar column
+			m.showAge = !m.showAge
+			if m.viewMode == ViewPorts {
+				m.updateTableRows()
+			}
+
+		case key.Matches(msg, m.keys.ServiceNames):
+			// Toggle registered service names in the Port column
+			m.showServices = !m.showServices
+			if m.viewMode == ViewPorts {
+				m.updateTableRows()
+			}
+
+		case key.Matches(msg, m.keys.Metrics):
 			// Toggle metrics display
 			m.showMetrics = !m.showMetrics
 			if m.viewMode == ViewPorts {
 				m.updateTableRows()
 			}
 
-		case "e", "E":
-			// Export current data
+		case key.Matches(msg, m.keys.Compact):
+			// Toggle compact layout
+			m.compact = !m.compact
+			m.resizeTable()
+			return m, m.savePreferences()
+
+		case key.Matches(msg, m.keys.Export):
+			// Export the port history in its view, else current data
+			if m.exportDirErr != nil {
+				m.err = m.exportDirErr
+				break
+			}
+			if m.viewMode == ViewHistory {
+				return m, exportHistory(m.historyTracker, m.exportDir)
+			}
 			if len(m.ports) > 0 {
-				return m, exportData(m.ports)
+				return m, exportData(m.ports, m.exportDir, m.exportFormats)
 			}
 		}
 
 	case tickMsg:
-		// Auto-refresh every 3 seconds
+		// Auto-refresh every refresh interval
 		return m, tea.Batch(
-			tickCmd(),
-			scanPorts(),
+			tickCmd(m.interval),
+			scanPorts(m.scanConfig),
 		)
 
 	case scanResultMsg:
-		m.ports = []scanner.PortInfo(msg)
+		// Overlapping scans (manual refresh plus tick) can finish out of
+		// order; applying an older result would replay stale state into
+		// the history tracker
+		if msg.started.Before(m.lastScanStart) {
+			return m, nil
+		}
+		m.lastScanStart = msg.started
+		m.allPorts = scanner.WithoutPorts(msg.ports, m.ignored)
 		m.lastScan = time.Now()
+		m.scanDuration = msg.duration
+		m.dockerErr = msg.dockerErr
+		m.notePermissionDenied(msg.denied)
 		m.isScanning = false
 		m.err = nil
 
-		// Update history tracker
-		m.historyTracker.Update(m.ports)
+		// Update history tracker and highlight changes since the last scan
+		firstScan := m.baselineAt.IsZero()
+		m.historyTracker.Update(m.allPorts)
+		m.runAutoKills(firstScan)
+		notifyCmd := m.runWatchNotifications(firstScan)
+		m.runNewPortAlerts(firstScan)
+		if firstScan {
+			m.baselineAt = time.Now()
+		}
+		m.diff.Apply(m.allPorts)
+		m.pruneSelection()
 
-		// Sort and update table
-		m.sortPorts()
-		if m.viewMode == ViewPorts {
+		// Filter, sort and update table
+		m.applyFilters()
+		switch m.viewMode {
+		case ViewPorts:
 			m.updateTableRows()
-		} else {
+		case ViewHistory:
 			m.updateHistoryTable()
+		case ViewStats:
+			m.updateStatsTable()
+		case ViewTop:
+			m.updateTopTable()
+		case ViewDetail:
+			return m, tea.Batch(notifyCmd, m.refreshDetail())
+		}
+		return m, notifyCmd
+
+	case autoExportTickMsg:
+		next := autoExportTick(m.autoExport.Interval)
+		if len(m.allPorts) == 0 {
+			return m, next
+		}
+		return m, tea.Batch(next, autoExport(m.allPorts, m.autoExport))
+
+	case autoExportDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.lastAutoExport = msg.at
 		}
 
+	case detailInfoMsg:
+		m.handleDetailInfo(msg)
+
+	case portQueryMsg:
+		return m, m.handlePortQuery(msg)
+
+	case portFreeMsg:
+		return m, m.handlePortFree(msg)
+
+	case reniceMsg:
+		return m, m.handleRenice(msg)
+
 	case exportSuccessMsg:
-		m.exportMsg = fmt.Sprintf("Exported to: %s", msg.path)
-		m.exportMsgTime = time.Now()
+		m.recordExport(msg.paths)
+		m.setStatus(fmt.Sprintf("Exported to: %s", strings.Join(msg.paths, ", ")))
 
 	case errorMsg:
 		m.err = msg.err
 		m.isScanning = false
 
+	case jumpTimeoutMsg:
+		// Only clear if no digit was typed since this timeout was scheduled
+		if msg.seq == m.jumpSeq {
+			m.jumpBuffer = ""
+		}
+
 	case tea.WindowSizeMsg:
 		// Handle window resize
-		m.table.SetHeight(msg.Height - 10)
+		m.height = msg.Height
+		m.resizeTable()
 	}
 
 	m.table, cmd = m.table.Update(msg)
@@ -288,40 +970,159 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) View() string {
 	var s string
 
+	// Blank spacer lines are dropped in compact mode
+	spacer := "\n"
+	if m.compact {
+		spacer = ""
+	}
+
 	// Title
-	if m.viewMode == ViewPorts {
+	switch {
+	case m.compact:
+		s += titleStyle.Render("GAZE") + "\n"
+	case m.viewMode == ViewPorts:
 		s += titleStyle.Render("🔍 GAZE - Local Port Monitor") + "\n\n"
-	} else {
+	case m.viewMode == ViewStats:
+		s += titleStyle.Render("📊 GAZE - Process Stats") + "\n\n"
+	case m.viewMode == ViewTop:
+		s += titleStyle.Render("🔥 GAZE - Top Talkers") + "\n\n"
+	case m.viewMode == ViewDetail:
+		s += titleStyle.Render("🔎 GAZE - Process Detail") + "\n\n"
+	default:
 		s += titleStyle.Render("📜 GAZE - Port History") + "\n\n"
 	}
 
 	// Table
-	s += m.table.View() + "\n\n"
+	switch {
+	case m.viewMode == ViewPorts && m.split:
+		s += m.renderSplit() + "\n" + spacer
+	case m.viewMode == ViewPorts:
+		s += colorizeDiffRows(m.table.View(), spanOf(m.table.Columns(), "HTTP")) + "\n" + spacer
+	case m.viewMode == ViewDetail:
+		s += m.renderDetail() + spacer
+	default:
+		s += m.table.View() + "\n" + spacer
+	}
 
 	// Status line
 	if m.viewMode == ViewPorts {
-		statusLine := fmt.Sprintf("Monitoring %d ports • Last scan: %s ago",
+		statusLine := fmt.Sprintf("Monitoring %d ports • Every %s • Last scan: %s ago",
 			len(m.ports),
+			m.interval,
 			time.Since(m.lastScan).Round(time.Second))
 
 		if m.isScanning {
 			statusLine += " • Scanning..."
 		}
+		if filter := m.filterStatus(); filter != "" {
+			statusLine += fmt.Sprintf(" • Filter: %s (%d of %d)", filter, len(m.ports), len(m.allPorts))
+		}
+		if hidden := len(m.ports) - m.shownPorts(); hidden > 0 {
+			statusLine += fmt.Sprintf(" • Showing first %d by sort, %d more hidden", m.shownPorts(), hidden)
+		}
 
+		s += statusStyle.Render(statusLine)
+		if m.scanDuration > 0 {
+			s += statusStyle.Render(" • ") + m.renderScanDuration()
+		}
+		if n := m.unexpectedPorts(); n > 0 {
+			s += errorStyle.Render(fmt.Sprintf(" • %d unexpected ports", n))
+		}
+		if n := m.backloggedPorts(); n > 0 {
+			s += warningStyle.Render(fmt.Sprintf(" • %d ports with accept backlog", n))
+		}
+		if m.autoKill != nil {
+			s += warningStyle.Render(fmt.Sprintf(" • auto-kill on (%d killed)", m.autoKill.killed))
+		}
+		if m.watcher != nil {
+			s += statusStyle.Render(" • watching " + m.watcher.String())
+		}
+		if m.alerter != nil {
+			s += warningStyle.Render(fmt.Sprintf(" • alerting on new ports (%d so far)", m.alerter.count))
+		}
+		if m.dockerErr != nil {
+			s += pidStyle.Render(" • docker unreachable")
+		}
+		if n := len(m.exports); n > 0 {
+			last := m.exports[n-1]
+			s += statusStyle.Render(fmt.Sprintf(" • Last export: %s at %s", filepath.Base(last.paths[0]), last.at.Format("15:04:05")))
+		}
+		if !m.lastAutoExport.IsZero() {
+			s += statusStyle.Render(fmt.Sprintf(" • Auto-exported %s", m.lastAutoExport.Format("15:04:05")))
+		}
+		if m.waitingPort != 0 {
+			s += warningStyle.Render(fmt.Sprintf(" • waiting for :%d to free...", m.waitingPort))
+		}
+		if m.exportDirErr != nil {
+			s += errorStyle.Render(fmt.Sprintf(" • exports disabled: %v", m.exportDirErr))
+		}
+		if m.stateOwner != 0 {
+			s += warningStyle.Render(fmt.Sprintf(" • settings locked by gaze PID %d, changes won't be saved", m.stateOwner))
+		}
+		if n := len(m.selected); n > 0 {
+			s += warningStyle.Render(fmt.Sprintf(" • %d selected, %s kills them", n, m.keys.Kill.Help().Key))
+		}
+		if m.jumpBuffer != "" {
+			s += statusStyle.Render(fmt.Sprintf(" • Jump to port: %s_", m.jumpBuffer))
+		}
+		s += "\n"
+	} else if m.viewMode == ViewDetail {
+		statusLine := fmt.Sprintf("Details as of the last scan, %s ago", time.Since(m.lastScan).Round(time.Second))
+		s += statusStyle.Render(statusLine) + "\n"
+	} else if m.viewMode == ViewTop {
+		statusLine := fmt.Sprintf("Heaviest %d processes holding the %d listed ports", topTalkersCount, len(m.ports))
+		s += statusStyle.Render(statusLine) + "\n"
+	} else if m.viewMode == ViewStats {
+		stats := m.historyTracker.GetStats()
+		statusLine := fmt.Sprintf("Processes: %d • Tracked: %d ports • Active: %d",
+			len(m.historyTracker.GetProcessStats()),
+			stats.TotalPortsTracked,
+			stats.ActivePorts)
 		s += statusStyle.Render(statusLine) + "\n"
 	} else {
 		// History view status
+		s += m.renderEventFeed(eventFeedSize) + spacer
+
 		stats := m.historyTracker.GetStats()
-		statusLine := fmt.Sprintf("Tracked: %d ports • Active: %d • Events: %d",
+		statusLine := fmt.Sprintf("Tracked: %d ports • Active: %d • Events: %d • Filter: %s",
 			stats.TotalPortsTracked,
 			stats.ActivePorts,
-			stats.TotalEvents)
+			stats.TotalEvents,
+			m.eventFilter)
+		if stats.TransientPorts > 0 {
+			statusLine += fmt.Sprintf(" • %d transient", stats.TransientPorts)
+		}
 		s += statusStyle.Render(statusLine) + "\n"
 	}
 
-	// Export success message (fade after 3 seconds)
-	if m.exportMsg != "" && time.Since(m.exportMsgTime) < 3*time.Second {
-		s += successStyle.Render(m.exportMsg) + "\n"
+	// Active prompt or confirmation
+	s += m.renderPrompt()
+	if m.portAnswer != "" {
+		s += explainStyle.Render(m.portAnswer) + "\n"
+	}
+	if m.showExports {
+		s += m.renderExports()
+	}
+	if m.showCaps {
+		s += m.renderCapabilities()
+	}
+
+	// Details about the highlighted port
+	if m.viewMode == ViewPorts {
+		if info := m.selectionInfo(); info != "" {
+			s += pidStyle.Render(info) + "\n"
+		}
+		if warning := m.selectionWarning(); warning != "" {
+			s += warningStyle.Render(warning) + "\n"
+		}
+		if m.explain {
+			s += m.renderExplain()
+		}
+	}
+
+	// Transient status message (fade after 3 seconds)
+	if m.statusMsg != "" && time.Since(m.statusMsgTime) < 3*time.Second {
+		s += successStyle.Render(m.statusMsg) + "\n"
 	}
 
 	// Error display
@@ -329,172 +1130,895 @@ func (m Model) View() string {
 		s += errorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n"
 	}
 
-	// Sort indicator (only in ports view)
-	if m.viewMode == ViewPorts {
+	// Sort indicator (hidden in compact mode)
+	if !m.compact {
 		sortInfo := m.getSortIndicator()
 		s += lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(sortInfo) + "\n"
 	}
 
 	// Help text
-	if m.viewMode == ViewPorts {
-		help := "↑/↓: Navigate • s: Sort • a: Order • m: Metrics • e: Export • h: History • k: Kill • r: Refresh • q: Quit"
-		s += helpStyle.Render(help)
-	} else {
-		help := "↑/↓: Navigate • h: Back to Ports • e: Export • q: Quit"
-		s += helpStyle.Render(help)
+	style := helpStyle
+	if m.compact {
+		style = style.Padding(0)
+	}
+	k := m.keys
+	switch m.viewMode {
+	case ViewPorts:
+		bindings := []key.Binding{k.Search, k.Select, k.Sort, k.SecondarySort, k.Order, k.Metrics, k.AgeBars, k.ServiceNames, k.Pin, k.Containers, k.OnlyProcess, k.Ignore,
+			k.Compact, k.Split, k.Export, k.ExportHistory, k.History, k.Stats, k.Top, k.Detail, k.Open, k.Explain, k.QueryPort, k.Capabilities,
+			k.Kill}
+		if scanner.SignalsSupported() {
+			bindings = append(bindings, k.ForceKill)
+		}
+		bindings = append(bindings, k.KillWait, k.KillRange)
+		if m.capabilityEnabled("renice") {
+			bindings = append(bindings, k.ReniceDown, k.ReniceUp)
+		}
+		if scanner.CanSuspend() {
+			bindings = append(bindings, k.Suspend)
+		}
+		bindings = append(bindings, k.Refresh, k.Slower, k.Faster, k.Quit)
+		s += style.Render(helpText("↑/↓ k/j: Navigate • 0-9: Jump to port", bindings...))
+	case ViewStats:
+		s += style.Render(helpText("↑/↓: Navigate", k.Stats, k.History, k.Export, k.Quit))
+	case ViewTop:
+		prefix := fmt.Sprintf("↑/↓: Navigate • %s: CPU/Memory", k.Sort.Help().Key)
+		s += style.Render(helpText(prefix, k.Top, k.Export, k.Quit))
+	case ViewDetail:
+		s += style.Render(helpText("esc: Back", k.Detail, k.Quit))
+	default:
+		s += style.Render(helpText("↑/↓: Navigate", k.Sort, k.Order, k.EventFilter, k.ClearHistory, k.History, k.Export, k.Quit))
 	}
 
 	return s
 }
 
-// tickCmd sends a tick message every 3 seconds
-func tickCmd() tea.Cmd {
-	return tea.Tick(3*time.Second, func(t time.Time) tea.Msg {
+// handleJumpKey processes keys for the quick-jump feature. It reports
+// whether the key was consumed.
+func (m *Model) handleJumpKey(key string) (bool, tea.Cmd) {
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+		// Ports never exceed 5 digits
+		if len(m.jumpBuffer) < 5 {
+			m.jumpBuffer += key
+		}
+		m.jumpSeq++
+		m.jumpToPort(m.jumpBuffer)
+		seq := m.jumpSeq
+		return true, tea.Tick(jumpTimeout, func(time.Time) tea.Msg {
+			return jumpTimeoutMsg{seq: seq}
+		})
+	}
+
+	if m.jumpBuffer == "" {
+		return false, nil
+	}
+
+	switch key {
+	case "enter", "esc":
+		m.jumpBuffer = ""
+		return true, nil
+	case "backspace":
+		m.jumpBuffer = m.jumpBuffer[:len(m.jumpBuffer)-1]
+		if m.jumpBuffer != "" {
+			m.jumpToPort(m.jumpBuffer)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// ignorePort hides a port from the table, history and exports, and drops
+// what was already recorded about it
+func (m *Model) ignorePort(port int) {
+	ignored := scanner.PortRange{Start: port, End: port}
+	m.ignored = append(slices.Clip(m.ignored), ignored)
+	m.newlyIgnored = append(m.newlyIgnored, port)
+
+	m.allPorts = scanner.WithoutPorts(m.allPorts, []scanner.PortRange{ignored})
+	m.applyFilters()
+	delete(m.pinned, port)
+	m.historyTracker.Forget(port)
+	m.diff.Forget(port)
+	m.updateTableRows()
+	m.setStatus(fmt.Sprintf("Ignoring port %d", port))
+}
+
+// selectPort moves the cursor to the row for port, if it is listed
+func (m *Model) selectPort(port int) {
+	for i, p := range m.ports[:m.shownPorts()] {
+		if p.Port == port {
+			m.table.SetCursor(i)
+			return
+		}
+	}
+}
+
+// jumpToPort moves the cursor to the port matching the typed digits,
+// preferring an exact match over the first port with that prefix
+func (m *Model) jumpToPort(digits string) {
+	prefixMatch := -1
+	for i, p := range m.ports[:m.shownPorts()] {
+		port := fmt.Sprintf("%d", p.Port)
+		if port == digits {
+			m.table.SetCursor(i)
+			return
+		}
+		if prefixMatch < 0 && strings.HasPrefix(port, digits) {
+			prefixMatch = i
+		}
+	}
+	if prefixMatch >= 0 {
+		m.table.SetCursor(prefixMatch)
+	}
+}
+
+// killFailure adds guidance to a kill error for display
+func killFailure(err error) error {
+	var killErr *scanner.KillError
+	if errors.As(err, &killErr) && killErr.Hint() != "" {
+		return fmt.Errorf("%w (%s)", err, killErr.Hint())
+	}
+	return err
+}
+
+// setStatus shows a transient message in the status area
+func (m *Model) setStatus(msg string) {
+	m.statusMsg = msg
+	m.statusMsgTime = time.Now()
+}
+
+// refuseReadOnly reports whether read-only mode rules out action,
+// explaining the refusal. Prompts check it before asking, so no
+// confirmation is requested for an action that can't happen.
+func (m *Model) refuseReadOnly(action string) bool {
+	if m.readOnly {
+		m.err = fmt.Errorf("read-only mode: refusing to %s", action)
+	}
+	return m.readOnly
+}
+
+// actionAllowed checks read-only and dry-run modes before a process is
+// modified. It reports whether the action should actually be performed.
+func (m *Model) actionAllowed(action string) bool {
+	if m.refuseReadOnly(action) {
+		return false
+	}
+	if m.dryRun {
+		m.setStatus(fmt.Sprintf("Dry run: would %s", action))
+		return false
+	}
+	return true
+}
+
+// renice adjusts the niceness of the process holding a port by delta
+func (m *Model) renice(p scanner.PortInfo, delta int32) tea.Cmd {
+	if p.PID == 0 {
+		return nil
+	}
+	if !scanner.CanRenice() {
+		m.err = fmt.Errorf("changing process priority is not supported on this platform")
+		return nil
+	}
+
+	niceness := scanner.StepNiceness(p.Niceness, delta)
+	if niceness == p.Niceness {
+		return nil
+	}
+	if !m.actionAllowed(fmt.Sprintf("renice PID %d (%s) to %d", p.PID, p.Process, niceness)) {
+		return nil
+	}
+
+	// renice runs in the background, since it may take up to its timeout
+	return func() tea.Msg {
+		err := scanner.ReniceProcess(p.PID, niceness)
+		return reniceMsg{pid: p.PID, process: p.Process, niceness: niceness, err: err}
+	}
+}
+
+// handleRenice reports how renicing went and rescans to show the new
+// niceness
+func (m *Model) handleRenice(msg reniceMsg) tea.Cmd {
+	if msg.err != nil {
+		m.err = fmt.Errorf("failed to renice process %d: %w", msg.pid, msg.err)
+		return nil
+	}
+	m.setStatus(fmt.Sprintf("Reniced PID %d (%s) to %d", msg.pid, msg.process, msg.niceness))
+	return scanPorts(m.scanConfig)
+}
+
+// toggleSuspend stops the process holding a port with SIGSTOP, or
+// continues it with SIGCONT if the last scan saw it stopped
+func (m *Model) toggleSuspend(p scanner.PortInfo) tea.Cmd {
+	if p.PID == 0 {
+		return nil
+	}
+	if !scanner.CanSuspend() {
+		m.err = fmt.Errorf("suspending processes is not supported on this platform")
+		return nil
+	}
+	resume := p.ProcState == scanner.ProcStopped
+	verb, signal := "suspend", scanner.SuspendProcess
+	if resume {
+		verb, signal = "resume", scanner.ResumeProcess
+	}
+	if !m.signalPermitted(p, verb) || !m.actionAllowed(fmt.Sprintf("%s PID %d (%s)", verb, p.PID, p.Process)) {
+		return nil
+	}
+
+	if err := signal(p.PID); err != nil {
+		m.err = err
+		return nil
+	}
+	if resume {
+		m.setStatus(fmt.Sprintf("Resumed PID %d (%s)", p.PID, p.Process))
+	} else {
+		m.setStatus(fmt.Sprintf("Suspended PID %d (%s); press %s again to resume", p.PID, p.Process, m.keys.Suspend.Help().Key))
+	}
+	return scanPorts(m.scanConfig)
+}
+
+// selectionInfo describes details of the highlighted port that don't fit
+// in the table
+func (m Model) selectionInfo() string {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.ports) {
+		return ""
+	}
+	p := m.ports[cursor]
+
+	var details []string
+	if p.ListenAddr != "" {
+		details = append(details, "bound to "+p.ListenAddr)
+	}
+	if p.ProcState != "" {
+		details = append(details, "state: "+procStateWord(p.ProcState))
+	}
+	if p.ServiceName != "" {
+		details = append(details, "service: "+p.ServiceName)
+	}
+	if h := m.historyTracker.GetHistory(history.KeyOf(p)); h != nil && h.IsActive && h.LastDownDuration > 0 {
+		details = append(details, fmt.Sprintf("back up after %s down (went down %s)",
+			history.FormatUptime(h.LastDownDuration), h.LastDownStart.Format("15:04:05")))
+	}
+	if p.DetectedServer != "" {
+		details = append(details, "server: "+p.DetectedServer)
+	}
+	if p.NetNS != "" {
+		binding := "host namespace"
+		if !p.InHostNetNS() {
+			binding = "separate namespace"
+		}
+		details = append(details, fmt.Sprintf("netns: %s (%s)", p.NetNS, binding))
+	}
+	if p.NumThreads >= 0 {
+		details = append(details, fmt.Sprintf("threads: %d", p.NumThreads))
+	}
+	if len(p.Upstreams) > 0 {
+		details = append(details, "proxies to: "+strings.Join(p.Upstreams, ", "))
+	}
+	for _, state := range []string{"ESTABLISHED", "CLOSE_WAIT", "TIME_WAIT"} {
+		if n := p.ConnStates[state]; n > 0 {
+			details = append(details, fmt.Sprintf("%s: %d", state, n))
+		}
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s • %s", socketLabel(p), strings.Join(details, " • "))
+}
+
+// socketLabel names a socket in messages, e.g. "Port 8080" or
+// "Unix socket /run/docker.sock"
+func socketLabel(p scanner.PortInfo) string {
+	if p.SocketType == scanner.SocketUnix {
+		return "Unix socket " + p.SocketPath
+	}
+	return fmt.Sprintf("Port %d", p.Port)
+}
+
+// selectionWarning flags signs of trouble with the highlighted port
+func (m Model) selectionWarning() string {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.ports) {
+		return ""
+	}
+	p := m.ports[cursor]
+
+	if n := p.ConnStates["CLOSE_WAIT"]; n >= scanner.CloseWaitWarnThreshold {
+		return fmt.Sprintf("⚠ %d connections in CLOSE_WAIT on port %d: %s may not be closing its connections", n, p.Port, p.Process)
+	}
+	return ""
+}
+
+// resizeTable fits the table to the terminal height, leaving room for the
+// title, status and help lines around it
+func (m *Model) resizeTable() {
+	if m.height == 0 {
+		return
+	}
+	chrome := 11
+	if m.compact {
+		chrome = 6
+	}
+	switch {
+	case m.viewMode == ViewHistory:
+		// Room for the event feed header and entries
+		chrome += eventFeedSize + 1
+	case m.split:
+		chrome += m.splitFeedRows() + 1
+	}
+	m.table.SetHeight(max(m.height-chrome, 1))
+}
+
+// preferences captures the view settings that persist between sessions.
+// Settings gaze only reads, such as history capacities, are carried over
+// from the file so saving doesn't drop them.
+func (m Model) preferences() config.Preferences {
+	prefs := config.LoadPreferences()
+
+	pinned := make([]int, 0, len(m.pinned))
+	for port := range m.pinned {
+		pinned = append(pinned, port)
+	}
+	sort.Ints(pinned)
+
+	// Ports ignored with the ignore key join the saved list, which an
+	// --ignore-ports from the environment leaves alone
+	ignored := slices.Clone(prefs.Ignored)
+	for _, port := range m.newlyIgnored {
+		if !slices.ContainsFunc(ignored, func(r scanner.PortRange) bool { return r.Contains(port) }) {
+			ignored = append(ignored, scanner.PortRange{Start: port, End: port})
+		}
+	}
+
+	prefs.Compact = m.compact
+	prefs.Pinned = pinned
+	prefs.Ignored = ignored
+	prefs.Sort = sortSpec(m.sortColumn, m.secondarySort)
+	prefs.SortDescending = !m.sortAscending
+	prefs.View = cmp.Or(viewNames[m.viewMode], viewNames[ViewPorts])
+	return prefs
+}
+
+// sortSpec formats a sort as accepted by ParseSort, e.g. "process,memory"
+func sortSpec(primary, secondary SortColumn) string {
+	spec := strings.ToLower(primary.String())
+	if secondary != NoSort {
+		spec += "," + strings.ToLower(secondary.String())
+	}
+	return spec
+}
+
+// portSet builds a lookup of the given ports
+func portSet(ports []int) map[int]bool {
+	set := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		set[port] = true
+	}
+	return set
+}
+
+// savePreferences persists the view preferences in the background, unless
+// another gaze instance owns the state files
+func (m Model) savePreferences() tea.Cmd {
+	if m.stateOwner != 0 {
+		return nil
+	}
+	prefs, seq := m.preferences(), m.prefsSaver.next.Add(1)
+	return func() tea.Msg {
+		if err := m.prefsSaver.save(prefs, seq); err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	}
+}
+
+// prefsSaver writes preferences one save at a time. Saves run in the
+// background and may finish out of order, so one that is older than the
+// last written is dropped rather than overwrite newer settings.
+type prefsSaver struct {
+	next  atomic.Uint64 // Sequence number of the latest save requested
+	mu    sync.Mutex
+	saved uint64 // Sequence number of the last save written
+}
+
+// save writes prefs, requested as save number seq, unless a later save
+// was already written
+func (s *prefsSaver) save(prefs config.Preferences, seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq < s.saved {
+		return nil
+	}
+	if err := config.SavePreferences(prefs); err != nil {
+		return err
+	}
+	s.saved = seq
+	return nil
+}
+
+// stepInterval moves the refresh interval to the next longer (dir > 0) or
+// shorter step. It takes effect from the next scan.
+func (m *Model) stepInterval(dir int) {
+	next := m.interval
+	if dir > 0 {
+		for _, step := range refreshSteps {
+			if step > m.interval {
+				next = step
+				break
+			}
+		}
+	} else {
+		for i := len(refreshSteps) - 1; i >= 0; i-- {
+			if refreshSteps[i] < m.interval {
+				next = refreshSteps[i]
+				break
+			}
+		}
+	}
+	m.interval = next
+	m.setStatus(fmt.Sprintf("Rescanning every %s", m.interval))
+}
+
+// renderScanDuration renders how long the last scan took, highlighting
+// scans that approach or exceed the refresh interval
+func (m Model) renderScanDuration() string {
+	text := fmt.Sprintf("scan took %s", m.scanDuration.Round(time.Millisecond))
+	switch {
+	case m.scanDuration >= m.interval:
+		return errorStyle.Render(text + " (slower than refresh interval)")
+	case m.scanDuration >= m.interval*3/4:
+		return warningStyle.Render(text + " (approaching refresh interval)")
+	}
+	return statusStyle.Render(text)
+}
+
+// tickCmd sends a tick message after the refresh interval
+func tickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
 // scanPorts runs the port scanner in the background
-func scanPorts() tea.Cmd {
+func scanPorts(cfg scanner.Config) tea.Cmd {
 	return func() tea.Msg {
-		ports, err := scanner.ScanPorts()
+		start := time.Now()
+		ports, err := scanner.ScanPorts(cfg)
 		if err != nil {
 			return errorMsg{err}
 		}
-		return scanResultMsg(ports)
+		slog.Debug("scan complete", "ports", len(ports), "duration", time.Since(start))
+		return scanResultMsg{
+			ports:     ports,
+			started:   start,
+			duration:  time.Since(start),
+			dockerErr: scanner.DockerError(),
+			denied:    scanner.PermissionDenied(),
+		}
 	}
 }
 
-// sortPorts sorts the ports based on current sort settings
+// sortPorts sorts the ports based on current sort settings: pinned ports
+// first, then by the sort column in the chosen order, then ascending by
+// the secondary sort column, and finally by port
 func (m *Model) sortPorts() {
 	sort.Slice(m.ports, func(i, j int) bool {
-		var less bool
-		switch m.sortColumn {
-		case SortByPort:
-			less = m.ports[i].Port < m.ports[j].Port
-		case SortByPID:
-			less = m.ports[i].PID < m.ports[j].PID
-		case SortByProcess:
-			less = m.ports[i].Process < m.ports[j].Process
+		a, b := m.ports[i], m.ports[j]
+
+		// Pinned ports always come first, sorted among themselves
+		pinnedA, pinnedB := m.pinned[a.Port], m.pinned[b.Port]
+		if pinnedA != pinnedB {
+			return pinnedA
 		}
+
+		c := m.comparePorts(a, b, m.sortColumn)
 		if !m.sortAscending {
-			return !less
+			c = -c
+		}
+		if c == 0 && m.secondarySort != NoSort {
+			c = m.comparePorts(a, b, m.secondarySort)
 		}
-		return less
+		if c == 0 {
+			c = m.comparePorts(a, b, SortByPort)
+		}
+		return c < 0
 	})
 }
 
+// comparePorts compares two ports by column, returning -1, 0 or +1
+func (m *Model) comparePorts(a, b scanner.PortInfo, column SortColumn) int {
+	switch column {
+	case SortByPort:
+		// Unix sockets have no port and sort by path before the TCP
+		// ports, or after them when descending. TCP sorts before UDP on
+		// the same port, then by address.
+		return cmp.Or(cmp.Compare(a.Port, b.Port), cmp.Compare(a.SocketType, b.SocketType),
+			compareAddrs(a.ListenAddr, b.ListenAddr), cmp.Compare(a.SocketPath, b.SocketPath))
+	case SortByPID:
+		return cmp.Compare(a.PID, b.PID)
+	case SortByProcess:
+		return cmp.Compare(a.Process, b.Process)
+	case SortByCPU:
+		return cmp.Compare(a.CPUPercent, b.CPUPercent)
+	case SortByMemory:
+		return cmp.Compare(a.MemoryMB, b.MemoryMB)
+	case SortByUptime:
+		return cmp.Compare(m.historyTracker.GetUptime(history.KeyOf(a)), m.historyTracker.GetUptime(history.KeyOf(b)))
+	case SortByAddress:
+		return compareAddrs(a.ListenAddr, b.ListenAddr)
+	case SortByLatency:
+		return cmp.Compare(a.Latency, b.Latency)
+	case SortByConnections:
+		return cmp.Compare(a.Connections, b.Connections)
+	}
+	return 0
+}
+
+// compareAddrs orders listen addresses numerically, IPv4 before IPv6.
+// Addresses that don't parse, such as the empty address of unix sockets,
+// come first, ordered as strings.
+func compareAddrs(a, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	switch {
+	case errA != nil && errB != nil:
+		return cmp.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return addrA.Compare(addrB)
+}
+
 // updateTableRows updates the table with current port data
 func (m *Model) updateTableRows() {
 	// Clear rows first to prevent index out of range panic when column count changes
 	m.table.SetRows([]table.Row{})
-	
+
 	// Update columns based on metrics toggle
 	var columns []table.Column
 	if m.showMetrics {
 		columns = []table.Column{
-			{Title: "Port", Width: 10},
+			{Title: "Port", Width: m.portWidth()},
+			{Title: "Proto", Width: 6},
 			{Title: "PID", Width: 10},
 			{Title: "Process", Width: 20},
 			{Title: "HTTP", Width: 8},
 			{Title: "Latency", Width: 10},
 			{Title: "CPU%", Width: 8},
 			{Title: "Mem(MB)", Width: 10},
+			{Title: "Nice", Width: 6},
+			{Title: "State", Width: 10},
+			{Title: "Queue", Width: 7},
+			{Title: "Threads", Width: 8},
 			{Title: "Uptime", Width: 12},
 		}
 	} else {
 		columns = []table.Column{
-			{Title: "Port", Width: 10},
+			{Title: "Port", Width: m.portWidth()},
+			{Title: "Proto", Width: 6},
+			{Title: "Address", Width: 16},
 			{Title: "PID", Width: 10},
-			{Title: "Process", Width: 25},
+			{Title: "Process", Width: 20},
+			{Title: "Command", Width: 30},
+			{Title: "User", Width: 10},
+			{Title: "Container", Width: m.containerWidth()},
 			{Title: "HTTP", Width: 8},
-			{Title: "Uptime", Width: 15},
-			{Title: "Status", Width: 10},
+			{Title: "Latency", Width: 10},
+			{Title: "Conns", Width: 7},
+			{Title: "Uptime", Width: 12},
+			{Title: "Status", Width: 11},
+		}
+		// Resource usage lives in the metrics view, but sorting by it
+		// shows the column being sorted on
+		switch m.sortColumn {
+		case SortByCPU:
+			columns = append(columns, table.Column{Title: "CPU%", Width: 8})
+		case SortByMemory:
+			columns = append(columns, table.Column{Title: "Mem(MB)", Width: 10})
+		}
+		if m.showAge {
+			columns = append(columns, table.Column{Title: "Age", Width: ageBarWidth + 1})
 		}
 	}
 	m.table.SetColumns(columns)
 
+	// Age bars are scaled against the longest uptime, found once rather
+	// than for every row
+	var longest time.Duration
+	if m.showAge {
+		longest = m.longestUptime()
+	}
+
 	rows := []table.Row{}
-	for _, p := range m.ports {
-		uptime := history.FormatUptime(m.historyTracker.GetUptime(p.Port))
-		
-		// HTTP status display
-		httpStatus := "-"
-		if p.HTTPStatus > 0 {
-			httpStatus = fmt.Sprintf("%d", p.HTTPStatus)
-		}
-		
-		// Latency display
-		latency := "-"
-		if p.Latency > 0 {
-			latency = fmt.Sprintf("%dms", p.Latency.Milliseconds())
-		}
-		
-		if m.showMetrics {
-			rows = append(rows, table.Row{
-				fmt.Sprintf("%d", p.Port),
-				fmt.Sprintf("%d", p.PID),
-				p.Process,
-				httpStatus,
-				latency,
-				fmt.Sprintf("%.1f", p.CPUPercent),
-				fmt.Sprintf("%.1f", p.MemoryMB),
-				uptime,
-			})
-		} else {
-			rows = append(rows, table.Row{
-				fmt.Sprintf("%d", p.Port),
-				fmt.Sprintf("%d", p.PID),
-				p.Process,
-				httpStatus,
-				uptime,
-				p.Status,
-			})
+	for _, p := range m.ports[:m.shownPorts()] {
+		portCell := m.portLabel(p)
+		if m.selected[selectionKeyOf(p)] {
+			portCell = selectedMarker + portCell
+		}
+		if m.pinned[p.Port] {
+			portCell = pinMarker + portCell
+		}
+		switch {
+		case m.alerter.isAlerted(p):
+			portCell = alertMarker + portCell
+		case m.isUnexpected(p):
+			portCell = unexpectedMarker + portCell
+		case m.isNewPort(p):
+			portCell = newPortMarker + portCell
+		case m.diff.IsAdded(p):
+			portCell = diffAddedMarker + portCell
+		}
+		rows = append(rows, m.buildPortRow(p, portCell, longest))
+	}
+
+	// Recently closed ports linger at the bottom before disappearing,
+	// unless the row limit already hides ports
+	removed := m.diff.Removed()
+	if m.shownPorts() < len(m.ports) {
+		removed = nil
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Port < removed[j].Port })
+	for _, p := range removed {
+		if !m.showsPort(p) {
+			continue
 		}
+		rows = append(rows, m.buildPortRow(p, diffRemovedMarker+m.portLabel(p), longest))
 	}
 	m.table.SetRows(rows)
 }
 
+// shownPorts returns how many of the sorted ports get table rows. Past
+// the row limit, ports are left out rather than built into rows nobody
+// can see without scrolling through thousands.
+func (m Model) shownPorts() int {
+	if m.maxRows > 0 && len(m.ports) > m.maxRows {
+		return m.maxRows
+	}
+	return len(m.ports)
+}
+
+// isUnexpected reports whether a port isn't allowed by the loaded baseline
+func (m Model) isUnexpected(p scanner.PortInfo) bool {
+	return m.baseline != nil && !m.baseline.Allows(p)
+}
+
+// unexpectedPorts counts listed ports not allowed by the baseline
+func (m Model) unexpectedPorts() int {
+	if m.baseline == nil {
+		return 0
+	}
+	return len(m.baseline.Unexpected(m.ports))
+}
+
+// isNewPort reports whether a port was first seen recently enough to be
+// highlighted. Ports that were already open when gaze started aren't new.
+func (m Model) isNewPort(p scanner.PortInfo) bool {
+	if m.highlightNew <= 0 {
+		return false
+	}
+	h := m.historyTracker.GetHistory(history.KeyOf(p))
+	if h == nil || !h.FirstSeen.After(m.baselineAt) {
+		return false
+	}
+	return time.Since(h.FirstSeen) < m.highlightNew
+}
+
+// buildPortRow builds the table row for a single port. Its age bar is
+// scaled against longest, the longest uptime listed.
+func (m *Model) buildPortRow(p scanner.PortInfo, portCell string, longest time.Duration) table.Row {
+	uptime := history.FormatUptime(m.historyTracker.GetUptime(history.KeyOf(p)))
+
+	// HTTP status display
+	httpStatus := "-"
+	if p.HTTPStatus > 0 {
+		httpStatus = fmt.Sprintf("%d", p.HTTPStatus)
+	}
+
+	// Latency display
+	latency := "-"
+	if p.Latency > 0 {
+		latency = fmt.Sprintf("%dms", p.Latency.Milliseconds())
+	}
+
+	if m.showMetrics {
+		return table.Row{
+			portCell,
+			p.SocketType,
+			fmt.Sprintf("%d", p.PID),
+			p.Process,
+			httpStatus,
+			latency,
+			fmt.Sprintf("%.1f", p.CPUPercent),
+			fmt.Sprintf("%.1f", p.MemoryMB),
+			fmt.Sprintf("%d", p.Niceness),
+			procStateWord(p.ProcState),
+			queueDepth(p.QueueDepth),
+			threadCount(p.NumThreads),
+			uptime,
+		}
+	}
+	container := "-"
+	if p.IsContainer {
+		container = p.ContainerName
+		if m.containersOnly {
+			container = fmt.Sprintf("%s (%s)", p.ContainerName, p.ContainerImage)
+		}
+	} else if !p.InHostNetNS() {
+		// Bound inside another network namespace, e.g. an unlabelled container
+		container = p.NetNS
+	}
+
+	status := p.Status
+	switch {
+	case m.isUnexpected(p):
+		status = "UNEXPECTED"
+	case p.ProcState == scanner.ProcStopped, p.ProcState == scanner.ProcZombie:
+		status = procStateWord(p.ProcState)
+	}
+
+	address := "-"
+	if p.ListenAddr != "" {
+		address = p.ListenAddr
+	}
+
+	row := table.Row{
+		portCell,
+		p.SocketType,
+		address,
+		fmt.Sprintf("%d", p.PID),
+		processCell(p),
+		cmp.Or(p.Cmdline, "-"),
+		cmp.Or(p.User, "-"),
+		container,
+		httpStatus,
+		latency,
+		connections(p),
+		uptime,
+		status,
+	}
+	switch m.sortColumn {
+	case SortByCPU:
+		row = append(row, fmt.Sprintf("%.1f", p.CPUPercent))
+	case SortByMemory:
+		row = append(row, fmt.Sprintf("%.1f", p.MemoryMB))
+	}
+	if m.showAge {
+		row = append(row, ageBar(m.historyTracker.GetUptime(history.KeyOf(p)), longest))
+	}
+	return row
+}
+
+// connections shows a TCP port's established connections; other sockets
+// have none to count
+func connections(p scanner.PortInfo) string {
+	if p.SocketType != scanner.SocketTCP {
+		return "-"
+	}
+	return fmt.Sprintf("%d", p.Connections)
+}
+
+// processCell names the process, marking ones gaze can't kill
+func processCell(p scanner.PortInfo) string {
+	if p.PID != 0 && !p.CanKill {
+		return lockMarker + p.Process
+	}
+	return p.Process
+}
+
+// queueDepth formats an accept queue depth, flagging non-zero backlogs
+func queueDepth(depth int) string {
+	switch {
+	case depth == scanner.QueueUnknown:
+		return "-"
+	case depth > 0:
+		return fmt.Sprintf("%d!", depth)
+	}
+	return "0"
+}
+
+// threadCount formats a thread count, which is -1 when not collected
+func threadCount(n int32) string {
+	if n < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// backloggedPorts counts ports with connections waiting to be accepted
+func (m Model) backloggedPorts() int {
+	n := 0
+	for _, p := range m.ports {
+		if p.QueueDepth > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// containerWidth widens the Container column when only container ports
+// are listed, to make room for the image
+func (m Model) containerWidth() int {
+	if m.containersOnly {
+		return 36
+	}
+	return 18
+}
+
+// portWidth returns the width of the Port column, wider when it also
+// shows service names
+func (m Model) portWidth() int {
+	if m.showServices {
+		return 24
+	}
+	return 10
+}
+
+// portLabel returns the text of a port's Port cell, e.g. "5432" or
+// "5432 (postgresql)" with service names shown
+func (m Model) portLabel(p scanner.PortInfo) string {
+	if m.showServices && p.WellKnown != "" {
+		return fmt.Sprintf("%s (%s)", p.Endpoint(), p.WellKnown)
+	}
+	return p.Endpoint()
+}
+
 // getSortIndicator returns a string showing the current sort state
 func (m Model) getSortIndicator() string {
-	var column string
-	switch m.sortColumn {
-	case SortByPort:
-		column = "Port"
-	case SortByPID:
-		column = "PID"
-	case SortByProcess:
-		column = "Process"
+	if m.viewMode == ViewStats {
+		return "Sorted by: Restarts ↓"
+	}
+	if m.viewMode == ViewTop {
+		if m.topByMemory {
+			return "Ranked by: Memory ↓"
+		}
+		return "Ranked by: CPU% ↓"
+	}
+	if m.viewMode == ViewHistory {
+		return fmt.Sprintf("Sorted by: %s %s", m.historySortColumn, sortDirection(m.historySortAscending))
 	}
 
-	direction := "↑"
-	if !m.sortAscending {
-		direction = "↓"
+	indicator := fmt.Sprintf("Sorted by: %s %s", m.sortColumn, sortDirection(m.sortAscending))
+	if m.secondarySort != NoSort {
+		indicator += fmt.Sprintf(", then %s %s", m.secondarySort, sortDirection(true))
 	}
+	return indicator
+}
 
-	return fmt.Sprintf("Sorted by: %s %s", column, direction)
+// sortDirection returns the arrow shown for a sort order
+func sortDirection(ascending bool) string {
+	if ascending {
+		return "↑"
+	}
+	return "↓"
 }
 
 // updateHistoryTable updates the table with port history data
 func (m *Model) updateHistoryTable() {
 	// Clear rows first to prevent index out of range panic when column count changes
 	m.table.SetRows([]table.Row{})
-	
+
 	// Update columns for history view
 	columns := []table.Column{
 		{Title: "Port", Width: 10},
 		{Title: "Process", Width: 25},
 		{Title: "Status", Width: 10},
-		{Title: "First Seen", Width: 20},
-		{Title: "Last Seen", Width: 20},
+		{Title: "First Seen", Width: 12},
+		{Title: "Last Seen", Width: 12},
 		{Title: "Uptime", Width: 15},
+		{Title: "Opens", Width: 6},
 	}
 	m.table.SetColumns(columns)
 
-	histories := m.historyTracker.GetAllHistory()
+	histories := m.historyTracker.GetAllHistorySorted(m.historySortColumn, m.historySortAscending)
 	rows := []table.Row{}
 
 	for _, h := range histories {
+		if !m.showsPortNumber(h.Port) {
+			continue
+		}
 		status := "CLOSED"
 		statusTime := h.LastSeen.Format("15:04:05")
 		if h.IsActive {
@@ -507,43 +2031,54 @@ func (m *Model) updateHistoryTable() {
 		}
 
 		rows = append(rows, table.Row{
-			fmt.Sprintf("%d", h.Port),
+			h.Key().String(),
 			h.Process,
 			status,
 			h.FirstSeen.Format("15:04:05"),
 			statusTime,
 			uptime,
+			fmt.Sprintf("%d", h.OpenCount),
 		})
 	}
 
 	m.table.SetRows(rows)
 }
 
-// exportData exports the current port data to files
-func exportData(ports []scanner.PortInfo) tea.Cmd {
+// exportHistory exports the port history to files in dir
+func exportHistory(tracker *history.Tracker, dir string) tea.Cmd {
 	return func() tea.Msg {
-		// Get home directory for exports
-		homeDir, err := os.UserHomeDir()
+		jsonPath, err := export.HistoryToJSON(tracker, dir)
 		if err != nil {
-			return errorMsg{fmt.Errorf("failed to get home directory: %w", err)}
+			return errorMsg{fmt.Errorf("failed to export history JSON: %w", err)}
 		}
-
-		exportDir := homeDir
-
-		// Export to both JSON and CSV
-		jsonPath, err := export.ToJSON(ports, exportDir)
+		csvPath, err := export.HistoryToCSV(tracker, dir)
 		if err != nil {
-			return errorMsg{fmt.Errorf("failed to export JSON: %w", err)}
+			return errorMsg{fmt.Errorf("failed to export history CSV: %w", err)}
 		}
+		return exportSuccessMsg{paths: []string{jsonPath, csvPath}}
+	}
+}
 
-		csvPath, err := export.ToCSV(ports, exportDir)
-		if err != nil {
-			return errorMsg{fmt.Errorf("failed to export CSV: %w", err)}
+// exportData exports the current port data to files in dir, as JSON and
+// CSV unless other formats are given
+func exportData(ports []scanner.PortInfo, dir string, formats []export.ExportFormat) tea.Cmd {
+	if len(formats) == 0 {
+		formats = []export.ExportFormat{export.FormatJSON, export.FormatCSV}
+	}
+	return func() tea.Msg {
+		var paths []string
+		for _, format := range formats {
+			exporter, ok := export.ExporterFor(format)
+			if !ok {
+				return errorMsg{fmt.Errorf("unsupported export format %q", format)}
+			}
+			path, err := exporter(ports, dir)
+			if err != nil {
+				return errorMsg{fmt.Errorf("failed to export %s: %w", strings.ToUpper(string(format)), err)}
+			}
+			paths = append(paths, path)
 		}
 
-		// Return success with both paths
-		paths := fmt.Sprintf("%s, %s", jsonPath, csvPath)
-		return exportSuccessMsg{path: paths}
+		return exportSuccessMsg{paths: paths}
 	}
 }
-
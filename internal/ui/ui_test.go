@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/junjiang/gaze/internal/config"
+	"github.com/junjiang/gaze/internal/history"
+)
+
+func TestPrefsSaverOrder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	s := &prefsSaver{}
+	first, second := s.next.Add(1), s.next.Add(1)
+
+	// The later save finishes first; the earlier one must not undo it
+	if err := s.save(config.Preferences{Sort: "process"}, second); err != nil {
+		t.Fatalf("save(%d) error = %v", second, err)
+	}
+	if err := s.save(config.Preferences{Sort: "port"}, first); err != nil {
+		t.Fatalf("save(%d) error = %v", first, err)
+	}
+
+	if got := config.LoadPreferences().Sort; got != "process" {
+		t.Errorf("saved Sort = %q, want %q", got, "process")
+	}
+}
+
+func BenchmarkUpdateTableRows(b *testing.B) {
+	const count = 5000
+
+	benchmarks := []struct {
+		maxRows int
+		showAge bool
+	}{
+		{maxRows: 0},
+		{maxRows: 0, showAge: true},
+		{maxRows: 200},
+	}
+
+	ports := make([]int, count)
+	for i := range ports {
+		ports[i] = 10000 + i
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(fmt.Sprintf("rows=%d/age=%v", bm.maxRows, bm.showAge), func(b *testing.B) {
+			// Keep the user's saved preferences out of the model
+			b.Setenv("HOME", b.TempDir())
+			b.Setenv("XDG_CONFIG_HOME", b.TempDir())
+
+			m := InitialModel(Options{
+				MaxEvents:     history.DefaultMaxEvents,
+				MaxHistories:  count,
+				MaxPortEvents: history.DefaultMaxPortEvents,
+				MaxRows:       bm.maxRows,
+			})
+			m.ports = listening(ports...)
+			m.allPorts = m.ports
+			m.historyTracker.Update(m.ports)
+			m.showAge = bm.showAge
+
+			b.ReportAllocs()
+			for b.Loop() {
+				m.updateTableRows()
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+// watchDebounce is the least time between two notifications about the
+// same port. Changes in between are summed up by one notification of the
+// port's latest state once it has passed.
+const watchDebounce = 30 * time.Second
+
+// portWatcher notifies the desktop when watched ports open or close. It
+// collects their events as the history tracker records them, for the
+// model to notify after each scan.
+type portWatcher struct {
+	ports    []scanner.PortRange
+	pending  map[history.PortKey]history.PortEvent // Latest unnotified event per port
+	notified map[history.PortKey]watchNotice       // Last notification per port
+}
+
+// watchNotice records a notification sent about a port
+type watchNotice struct {
+	event history.EventType
+	at    time.Time
+}
+
+// newPortWatcher watches ports as tracked by tracker
+func newPortWatcher(ports []scanner.PortRange, tracker *history.Tracker) *portWatcher {
+	w := &portWatcher{
+		ports:    ports,
+		pending:  make(map[history.PortKey]history.PortEvent),
+		notified: make(map[history.PortKey]watchNotice),
+	}
+	tracker.Subscribe(func(e history.PortEvent) {
+		if w.watches(e.Port) {
+			w.pending[e.Key()] = e
+		}
+	})
+	return w
+}
+
+// watches reports whether port is one of the watched ports
+func (w *portWatcher) watches(port int) bool {
+	return slices.ContainsFunc(w.ports, func(r scanner.PortRange) bool { return r.Contains(port) })
+}
+
+// seed takes the watched ports among those open at startup as already
+// notified open, so that their opening, which --stable-scans may only
+// report a few scans later, isn't news but their closing is
+func (w *portWatcher) seed(ports []scanner.PortInfo) {
+	clear(w.pending)
+	for _, p := range ports {
+		if w.watches(p.Port) {
+			w.notified[history.KeyOf(p)] = watchNotice{event: history.EventPortOpened}
+		}
+	}
+}
+
+// due returns the events to notify now, and forgets those that only
+// restate what was last notified. Events for ports notified within
+// watchDebounce stay pending.
+func (w *portWatcher) due(now time.Time) []history.PortEvent {
+	var events []history.PortEvent
+	for key, e := range w.pending {
+		last, seen := w.notified[key]
+		switch {
+		case seen && last.event == e.EventType:
+			// Flapped back to the state last notified
+			delete(w.pending, key)
+		case seen && now.Sub(last.at) < watchDebounce:
+			// Too soon; notify the latest state later
+		default:
+			delete(w.pending, key)
+			w.notified[key] = watchNotice{event: e.EventType, at: now}
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// runWatchNotifications notifies the desktop of watched ports that opened
+// or closed in the last scan. Ports already open when gaze started aren't
+// news.
+func (m *Model) runWatchNotifications(firstScan bool) tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	if firstScan {
+		m.watcher.seed(m.allPorts)
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for _, e := range m.watcher.due(time.Now()) {
+		title, body := watchNotification(e)
+		slog.Info("watched port changed", "port", e.Port, "protocol", e.Protocol, "event", e.EventType, "process", e.Process)
+		cmds = append(cmds, func() tea.Msg {
+			if err := notify(title, body); err != nil {
+				return errorMsg{err}
+			}
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// watchNotification words the notification for a watched port's event
+func watchNotification(e history.PortEvent) (title, body string) {
+	port := e.Key().String()
+	if e.EventType == history.EventPortOpened {
+		return fmt.Sprintf("Port %s opened", port), fmt.Sprintf("%s (PID %d) is listening on port %s", e.Process, e.PID, port)
+	}
+	return fmt.Sprintf("Port %s closed", port), fmt.Sprintf("%s (PID %d) stopped listening on port %s", e.Process, e.PID, port)
+}
+
+// String lists the watched ports for the status line, e.g. ":4000, :8080"
+func (w *portWatcher) String() string {
+	ranges := make([]string, len(w.ports))
+	for i, r := range w.ports {
+		ranges[i] = ":" + r.String()
+	}
+	return strings.Join(ranges, ", ")
+}
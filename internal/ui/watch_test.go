@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/junjiang/gaze/internal/history"
+	"github.com/junjiang/gaze/internal/scanner"
+)
+
+func TestPortWatcherDue(t *testing.T) {
+	type notice struct {
+		port  int
+		event history.EventType
+	}
+	tests := []struct {
+		name   string
+		stable int
+		scans  [][]int
+		want   []notice
+	}{
+		{
+			name:   "startup port quiet",
+			stable: 1,
+			scans:  [][]int{{3000}, {3000}},
+			want:   nil,
+		},
+		{
+			name:   "startup port quiet with stable scans",
+			stable: 2,
+			scans:  [][]int{{3000}, {3000}, {3000}},
+			want:   nil,
+		},
+		{
+			name:   "startup port closing notified",
+			stable: 2,
+			scans:  [][]int{{3000}, {3000}, {}},
+			want:   []notice{{3000, history.EventPortClosed}},
+		},
+		{
+			name:   "new port notified once stable",
+			stable: 2,
+			scans:  [][]int{{}, {3000}, {3000}},
+			want:   []notice{{3000, history.EventPortOpened}},
+		},
+		{
+			name:   "unwatched port quiet",
+			stable: 1,
+			scans:  [][]int{{}, {8080}},
+			want:   nil,
+		},
+	}
+
+	watched, err := scanner.ParsePortList("3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := history.NewTracker(history.DefaultMaxEvents, history.DefaultMaxHistories, history.DefaultMaxPortEvents, history.DefaultMaxSamples)
+			tracker.SetStableThreshold(tt.stable)
+			w := newPortWatcher(watched, tracker)
+
+			var got []notice
+			now := time.Now()
+			for i, scan := range tt.scans {
+				ports := listening(scan...)
+				tracker.Update(ports)
+				if i == 0 {
+					w.seed(ports)
+					continue
+				}
+				for _, e := range w.due(now.Add(time.Duration(i) * time.Minute)) {
+					got = append(got, notice{e.Port, e.EventType})
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("notified %v, want %v", got, tt.want)
+			}
+		})
+	}
+}